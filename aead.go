@@ -0,0 +1,350 @@
+package storj
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// EncryptionMode selects how Upload encrypts files and which files Download
+// accepts.
+type EncryptionMode int
+
+const (
+	// EncryptionCTR is AES-256-CTR with keys derived following libstorj's
+	// scheme. It is the default. It does not detect tampering.
+	// Download in this mode also reads EncryptionAEAD files, but without
+	// protection against their header being altered.
+	EncryptionCTR EncryptionMode = iota
+
+	// EncryptionAEAD is chunked AES-256-GCM. Any change to the stored data,
+	// including truncating or reordering chunks or altering the header, makes
+	// Download fail with ErrTampered. Download in this mode refuses CTR
+	// files. Other clients cannot read files in this mode.
+	EncryptionAEAD
+)
+
+// DefaultAEADChunkSize is the amount of plaintext sealed in each chunk in
+// EncryptionAEAD mode.
+const DefaultAEADChunkSize = 64 << 10
+
+// maxAEADChunkSize bounds the chunk size Download accepts from a header, so a
+// corrupt header cannot make it allocate without limit.
+const maxAEADChunkSize = 16 << 20
+
+// aeadChunkSize is a variable so tests can seal several chunks cheaply.
+var aeadChunkSize = DefaultAEADChunkSize
+
+// ErrTampered is returned when an encrypted file fails authentication.
+var ErrTampered = errors.New("encrypted file failed authentication")
+
+// A file in EncryptionAEAD mode starts with a header, which is authenticated
+// along with every chunk:
+//
+//	magic        6 bytes  "SJAEAD"
+//	version      1 byte   1
+//	algorithm    1 byte   1 for AES-256-GCM
+//	chunk size   4 bytes  plaintext bytes per chunk, big endian
+//	nonce prefix 7 bytes  random
+//
+// Chunk i is sealed with the nonce prefix || i (4 bytes, big endian) || 1 if
+// it is the last chunk, else 0. Every file ends with a last chunk, which may
+// be empty, so truncation is detected.
+//
+// CTR ciphertext is indistinguishable from random data, so the chance of a
+// CTR file starting with the magic is 2^-48.
+const (
+	aeadMagic      = "SJAEAD"
+	aeadVersion    = 1
+	aeadAESGCM     = 1
+	aeadPrefixSize = 7
+	aeadHeaderSize = len(aeadMagic) + 2 + 4 + aeadPrefixSize
+)
+
+// aeadKey derives the AES-GCM key for a file from its key ID (see fileKey).
+// It is kept apart from the CTR key so the two modes never share a keystream.
+func aeadKey(seed []byte, bucketID, keyID string) ([]byte, error) {
+	key, err := fileKey(seed, bucketID, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("storj aead v1"))
+	return mac.Sum(nil), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// aeadState is what sealing and opening chunks share.
+type aeadState struct {
+	aead   cipher.AEAD
+	header []byte
+	index  uint32
+	buf    bytes.Buffer
+	err    error
+}
+
+func (s *aeadState) nonce(last bool) ([]byte, error) {
+	if s.index == 1<<32-1 {
+		return nil, fmt.Errorf("encrypted file has too many chunks")
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	copy(nonce, s.header[aeadHeaderSize-aeadPrefixSize:])
+	binary.BigEndian.PutUint32(nonce[aeadPrefixSize:], s.index)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	s.index++
+
+	return nonce, nil
+}
+
+// aeadEncrypter reads plaintext from src and returns the header followed by
+// sealed chunks.
+type aeadEncrypter struct {
+	aeadState
+	src   *bufio.Reader
+	chunk []byte
+	done  bool
+}
+
+func newAEADEncrypter(key []byte, src io.Reader) (io.Reader, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, aeadHeaderSize)
+	copy(header, aeadMagic)
+	header[len(aeadMagic)] = aeadVersion
+	header[len(aeadMagic)+1] = aeadAESGCM
+	binary.BigEndian.PutUint32(header[len(aeadMagic)+2:], uint32(aeadChunkSize))
+	if _, err := io.ReadFull(rand.Reader, header[aeadHeaderSize-aeadPrefixSize:]); err != nil {
+		return nil, err
+	}
+
+	e := &aeadEncrypter{
+		aeadState: aeadState{aead: aead, header: header},
+		src:       bufio.NewReader(src),
+		chunk:     make([]byte, aeadChunkSize),
+	}
+	e.buf.Write(header)
+
+	return e, nil
+}
+
+func (e *aeadEncrypter) Read(p []byte) (int, error) {
+	for e.buf.Len() == 0 {
+		if e.err != nil {
+			return 0, e.err
+		}
+		if e.done {
+			return 0, io.EOF
+		}
+		e.err = e.seal()
+	}
+
+	return e.buf.Read(p)
+}
+
+// seal reads and seals the next chunk into buf.
+func (e *aeadEncrypter) seal() error {
+	n, err := io.ReadFull(e.src, e.chunk)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+
+	last := n < len(e.chunk)
+	if !last {
+		// A full chunk is the last one only if nothing follows it.
+		if _, err := e.src.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	nonce, err := e.nonce(last)
+	if err != nil {
+		return err
+	}
+
+	e.buf.Write(e.aead.Seal(nil, nonce, e.chunk[:n], e.header))
+	e.done = last
+	return nil
+}
+
+// aeadDecrypter reads the header and sealed chunks from src and returns the
+// plaintext, failing with ErrTampered as soon as a chunk does not
+// authenticate.
+type aeadDecrypter struct {
+	aeadState
+	key   []byte
+	src   io.Reader
+	chunk []byte
+	done  bool
+}
+
+func (d *aeadDecrypter) Read(p []byte) (int, error) {
+	for d.buf.Len() == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.done {
+			return 0, io.EOF
+		}
+		if d.aead == nil {
+			d.err = d.readHeader()
+		} else {
+			d.err = d.open()
+		}
+	}
+
+	return d.buf.Read(p)
+}
+
+func (d *aeadDecrypter) readHeader() error {
+	header := make([]byte, aeadHeaderSize)
+	if _, err := io.ReadFull(d.src, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return ErrTampered
+		}
+		return err
+	}
+
+	if string(header[:len(aeadMagic)]) != aeadMagic {
+		return ErrTampered
+	}
+	if v := header[len(aeadMagic)]; v != aeadVersion {
+		return fmt.Errorf("unsupported encrypted file version %d", v)
+	}
+	if alg := header[len(aeadMagic)+1]; alg != aeadAESGCM {
+		return fmt.Errorf("unsupported encryption algorithm %d", alg)
+	}
+
+	size := binary.BigEndian.Uint32(header[len(aeadMagic)+2:])
+	if size == 0 || size > maxAEADChunkSize {
+		return fmt.Errorf("invalid encrypted chunk size %d", size)
+	}
+
+	aead, err := newGCM(d.key)
+	if err != nil {
+		return err
+	}
+
+	d.aead = aead
+	d.header = header
+	d.chunk = make([]byte, int(size)+aead.Overhead())
+	return nil
+}
+
+// open reads and opens the next chunk into buf.
+func (d *aeadDecrypter) open() error {
+	n, err := io.ReadFull(d.src, d.chunk)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+
+	// Only the last chunk is short, but a full one may be last too.
+	last := n < len(d.chunk)
+	nonce, err := d.nonce(last)
+	if err != nil {
+		return err
+	}
+
+	plain, err := d.aead.Open(nil, nonce, d.chunk[:n], d.header)
+	if err != nil && !last {
+		// Retry as the last chunk, which can be full.
+		nonce[len(nonce)-1] = 1
+		plain, err = d.aead.Open(nil, nonce, d.chunk[:n], d.header)
+		last = true
+	}
+	if err != nil {
+		return ErrTampered
+	}
+
+	if last {
+		// Nothing may follow the last chunk.
+		var extra [1]byte
+		if n, _ := io.ReadFull(d.src, extra[:]); n > 0 {
+			return ErrTampered
+		}
+	}
+
+	d.buf.Write(plain)
+	d.done = last
+	return nil
+}
+
+// decrypter picks the decryption for a downloaded file on its first Read,
+// from whether the data starts with the EncryptionAEAD magic. If requireAEAD
+// is set, data without the magic fails with ErrTampered instead of being
+// decrypted as CTR, since changing the magic would otherwise strip the
+// authentication.
+type decrypter struct {
+	seed        []byte
+	bucketID    string
+	file        *File
+	requireAEAD bool
+	src         io.ReadCloser
+
+	r   io.Reader
+	err error
+}
+
+func (d *decrypter) Read(p []byte) (int, error) {
+	if d.r == nil && d.err == nil {
+		d.err = d.init()
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	return d.r.Read(p)
+}
+
+func (d *decrypter) init() error {
+	br := bufio.NewReader(d.src)
+	magic, err := br.Peek(len(aeadMagic))
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	if string(magic) == aeadMagic {
+		key, err := aeadKey(d.seed, d.bucketID, keyID(d.file))
+		if err != nil {
+			return err
+		}
+		d.r = &aeadDecrypter{key: key, src: br}
+		return nil
+	}
+	if d.requireAEAD {
+		return ErrTampered
+	}
+
+	stream, err := fileStream(d.seed, d.bucketID, d.file)
+	if err != nil {
+		return err
+	}
+	d.r = &cipher.StreamReader{S: stream, R: br}
+	return nil
+}
+
+func (d *decrypter) Close() error {
+	return d.src.Close()
+}
@@ -0,0 +1,226 @@
+package storj
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+)
+
+var testAEADKey = bytes.Repeat([]byte{7}, 32)
+
+func aeadEncrypt(t *testing.T, plaintext []byte) []byte {
+	r, err := newAEADEncrypter(testAEADKey, bytes.NewReader(plaintext))
+	if err != nil {
+		t.Fatalf("newAEADEncrypter returned error: %v", err)
+	}
+
+	ciphertext, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("encrypting returned error: %v", err)
+	}
+	return ciphertext
+}
+
+func aeadDecrypt(ciphertext []byte) ([]byte, error) {
+	return ioutil.ReadAll(&aeadDecrypter{key: testAEADKey, src: bytes.NewReader(ciphertext)})
+}
+
+func TestAEADRoundTrip(t *testing.T) {
+	defer func(n int) { aeadChunkSize = n }(aeadChunkSize)
+	aeadChunkSize = 16
+
+	for _, size := range []int{0, 1, 15, 16, 17, 48, 50} {
+		plaintext := bytes.Repeat([]byte{'x'}, size)
+		ciphertext := aeadEncrypt(t, plaintext)
+
+		chunks := (size + 15) / 16
+		if chunks == 0 {
+			chunks = 1
+		}
+		if expected := aeadHeaderSize + size + chunks*16; len(ciphertext) != expected {
+			t.Errorf("size %d: ciphertext is %d bytes, expected %d", size, len(ciphertext), expected)
+		}
+
+		got, err := aeadDecrypt(ciphertext)
+		if err != nil {
+			t.Errorf("size %d: decrypting returned error: %v", size, err)
+			continue
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("size %d: decrypted %q, expected %q", size, got, plaintext)
+		}
+	}
+}
+
+func TestAEADHeaderChunkSize(t *testing.T) {
+	defer func(n int) { aeadChunkSize = n }(aeadChunkSize)
+	aeadChunkSize = 16
+
+	plaintext := []byte("the chunk size comes from the header")
+	ciphertext := aeadEncrypt(t, plaintext)
+
+	aeadChunkSize = DefaultAEADChunkSize
+	got, err := aeadDecrypt(ciphertext)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("decrypted %q, %v, expected %q", got, err, plaintext)
+	}
+}
+
+func TestAEADTampering(t *testing.T) {
+	defer func(n int) { aeadChunkSize = n }(aeadChunkSize)
+	aeadChunkSize = 16
+
+	// Three full chunks, the last of which is sealed as such.
+	plaintext := bytes.Repeat([]byte("0123456789abcdef"), 3)
+	ciphertext := aeadEncrypt(t, plaintext)
+	chunk := 16 + 16
+
+	tests := map[string]func(c []byte) []byte{
+		"flipped header bit": func(c []byte) []byte {
+			c[aeadHeaderSize-1] ^= 1
+			return c
+		},
+		"flipped data bit": func(c []byte) []byte {
+			c[aeadHeaderSize+chunk+3] ^= 1
+			return c
+		},
+		"truncated at chunk boundary": func(c []byte) []byte {
+			return c[:aeadHeaderSize+chunk]
+		},
+		"last chunk dropped": func(c []byte) []byte {
+			return c[:len(c)-chunk]
+		},
+		"truncated mid chunk": func(c []byte) []byte {
+			return c[:aeadHeaderSize+chunk+5]
+		},
+		"appended data": func(c []byte) []byte {
+			return append(c, 0)
+		},
+		"chunks swapped": func(c []byte) []byte {
+			first := append([]byte{}, c[aeadHeaderSize:aeadHeaderSize+chunk]...)
+			copy(c[aeadHeaderSize:], c[aeadHeaderSize+chunk:aeadHeaderSize+2*chunk])
+			copy(c[aeadHeaderSize+chunk:], first)
+			return c
+		},
+		"header only": func(c []byte) []byte {
+			return c[:aeadHeaderSize]
+		},
+	}
+
+	for name, tamper := range tests {
+		c := tamper(append([]byte{}, ciphertext...))
+		if _, err := aeadDecrypt(c); err != ErrTampered {
+			t.Errorf("%s: expected ErrTampered, got %v", name, err)
+		}
+	}
+}
+
+func TestAEADBadHeader(t *testing.T) {
+	ciphertext := aeadEncrypt(t, []byte("data"))
+
+	c := append([]byte{}, ciphertext...)
+	c[len(aeadMagic)] = 2
+	if _, err := aeadDecrypt(c); err == nil || err == ErrTampered {
+		t.Errorf("expected an unsupported version error, got %v", err)
+	}
+
+	c = append([]byte{}, ciphertext...)
+	c[len(aeadMagic)+2] = 0xff
+	if _, err := aeadDecrypt(c); err == nil || err == ErrTampered {
+		t.Errorf("expected an invalid chunk size error, got %v", err)
+	}
+}
+
+func TestDecrypterDetectsMode(t *testing.T) {
+	seed := MnemonicToSeed(testMnemonic)
+	bucketID, file := testBucketID, &File{ID: testFileID, Index: testIndex}
+	plaintext := []byte("same plaintext, either mode")
+
+	stream, _ := fileStream(seed, bucketID, file)
+	ctr := make([]byte, len(plaintext))
+	stream.XORKeyStream(ctr, plaintext)
+
+	key, _ := aeadKey(seed, bucketID, file.Index)
+	r, _ := newAEADEncrypter(key, bytes.NewReader(plaintext))
+	aead, _ := ioutil.ReadAll(r)
+
+	for name, ciphertext := range map[string][]byte{"ctr": ctr, "aead": aead} {
+		d := &decrypter{seed: seed, bucketID: bucketID, file: file, src: ioutil.NopCloser(bytes.NewReader(ciphertext))}
+		got, err := ioutil.ReadAll(d)
+		if err != nil || !bytes.Equal(got, plaintext) {
+			t.Errorf("%s: decrypted %q, %v, expected %q", name, got, err, plaintext)
+		}
+	}
+
+	d := &decrypter{seed: seed, bucketID: bucketID, file: file, requireAEAD: true, src: ioutil.NopCloser(bytes.NewReader(ctr))}
+	if got, err := ioutil.ReadAll(d); err != ErrTampered {
+		t.Errorf("decrypter requiring AEAD returned %q, %v for CTR data, expected ErrTampered", got, err)
+	}
+}
+
+func TestFilesDownloadAEADMagicFlipped(t *testing.T) {
+	bucketID, fileID := "0123456789abcdef01234567", "abcdef0123456789abcdef01"
+	plaintext := []byte("0123456789")
+
+	// Upload in AEAD mode and keep what the farmer stored.
+	setup()
+	enableAuth()
+	client.seed = MnemonicToSeed(testMnemonic)
+	client.encryptionMode = EncryptionAEAD
+	shards, created := handleUpload(t)
+
+	_, err := client.Files.Upload(context.Background(), bucketID, "digits.txt", bytes.NewReader(plaintext))
+	disableAuth()
+	teardown()
+	if err != nil {
+		t.Fatalf("Files.Upload returned error: %v", err)
+	}
+	if len(shards) != 1 {
+		t.Fatalf("farmer received %d shards, expected 1", len(shards))
+	}
+
+	var stored []byte
+	for _, data := range shards {
+		stored = data
+	}
+	stored[0] ^= 1
+
+	// Serve the altered data from a Bridge that records its hash, as a
+	// Bridge working with the farmer could.
+	setup()
+	defer teardown()
+	enableAuth()
+	defer disableAuth()
+	client.seed = MnemonicToSeed(testMnemonic)
+	client.encryptionMode = EncryptionAEAD
+
+	handleDownloadFile(t, bucketID, fileID, (*created)["index"], []string{string(stored)}, nil)
+
+	rc, err := client.Files.Download(context.Background(), bucketID, fileID)
+	if err != nil {
+		t.Fatalf("Files.Download returned error: %v", err)
+	}
+	defer rc.Close()
+
+	if got, err := ioutil.ReadAll(rc); err != ErrTampered {
+		t.Errorf("reading download returned %q, %v, expected ErrTampered", got, err)
+	}
+}
+
+func TestWithEncryptionMode(t *testing.T) {
+	if _, err := NewClient(WithEncryptionMode(EncryptionAEAD)); err == nil {
+		t.Errorf("EncryptionAEAD without WithEncryption should be rejected")
+	}
+	if _, err := NewClient(WithEncryption(testMnemonic), WithEncryptionMode(EncryptionMode(9))); err == nil {
+		t.Errorf("an unknown encryption mode should be rejected")
+	}
+
+	c, err := NewClient(WithEncryptionMode(EncryptionAEAD), WithEncryption(testMnemonic))
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+	if c.encryptionMode != EncryptionAEAD {
+		t.Errorf("encryption mode is %d, expected EncryptionAEAD", c.encryptionMode)
+	}
+}
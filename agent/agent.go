@@ -0,0 +1,133 @@
+// Package agent implements a signing agent for Storj Bridge requests, in the
+// spirit of ssh-agent. A long-running Agent holds decrypted auth keys and
+// signs request digests for clients connecting over a Unix domain socket, so
+// short-lived processes never need to load private key material themselves.
+package agent
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/mlayne/storj"
+)
+
+// SocketEnv is the environment variable holding the agent's socket path.
+const SocketEnv = "STORJ_AUTH_SOCK"
+
+const (
+	opKeys = "keys"
+	opSign = "sign"
+)
+
+// request and response are exchanged as newline-delimited JSON.
+type request struct {
+	Op     string `json:"op"`
+	Key    string `json:"key,omitempty"`
+	Digest string `json:"digest,omitempty"`
+}
+
+type response struct {
+	Keys      []string `json:"keys,omitempty"`
+	Signature string   `json:"signature,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// Agent holds private keys in memory and signs digests on behalf of clients.
+type Agent struct {
+	mu   sync.RWMutex
+	keys map[string]*btcec.PrivateKey
+}
+
+// New returns an Agent holding no keys.
+func New() *Agent {
+	return &Agent{keys: make(map[string]*btcec.PrivateKey)}
+}
+
+// Add makes key available for signing.
+func (a *Agent) Add(key *btcec.PrivateKey) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.keys[storj.PubKeyHex(key.PubKey())] = key
+}
+
+// Remove forgets the key with the given public key.
+func (a *Agent) Remove(pub *btcec.PublicKey) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.keys, storj.PubKeyHex(pub))
+}
+
+// Serve accepts connections on l until it is closed.
+func (a *Agent) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go a.serveConn(conn)
+	}
+}
+
+func (a *Agent) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(conn))
+	enc := json.NewEncoder(conn)
+	for {
+		var req request
+		if err := dec.Decode(&req); err != nil {
+			return
+		}
+
+		resp, err := a.handle(&req)
+		if err != nil {
+			resp = &response{Error: err.Error()}
+		}
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+func (a *Agent) handle(req *request) (*response, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	switch req.Op {
+	case opKeys:
+		resp := &response{Keys: []string{}}
+		for k := range a.keys {
+			resp.Keys = append(resp.Keys, k)
+		}
+		return resp, nil
+
+	case opSign:
+		key, ok := a.keys[req.Key]
+		if !ok {
+			return nil, errors.New("unknown key")
+		}
+
+		digest, err := hex.DecodeString(req.Digest)
+		if err != nil {
+			return nil, errors.New("malformed digest")
+		}
+		if len(digest) != 32 {
+			return nil, errors.New("digest must be 32 bytes")
+		}
+
+		sig, err := key.Sign(digest)
+		if err != nil {
+			return nil, err
+		}
+		return &response{Signature: hex.EncodeToString(sig.Serialize())}, nil
+	}
+
+	return nil, errors.New("unknown operation")
+}
@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+func startAgent(t *testing.T, keys ...*btcec.PrivateKey) (*Client, func()) {
+	dir, err := ioutil.TempDir("", "storj-agent-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "agent.sock")
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := New()
+	for _, k := range keys {
+		a.Add(k)
+	}
+	go a.Serve(l)
+
+	c, err := Dial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c, func() {
+		c.Close()
+		l.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestAgentSign(t *testing.T) {
+	key, _ := btcec.NewPrivateKey(btcec.S256())
+	c, done := startAgent(t, key)
+	defer done()
+
+	signer, err := c.DefaultSigner()
+	if err != nil {
+		t.Fatalf("DefaultSigner returned error: %v", err)
+	}
+	if !signer.PublicKey().IsEqual(key.PubKey()) {
+		t.Errorf("agent returned the wrong public key")
+	}
+
+	digest := sha256.Sum256([]byte("GET\n/buckets\n__nonce=abc"))
+	sig, err := signer.Sign(digest[:])
+	if err != nil {
+		t.Fatalf("Sign returned error: %v", err)
+	}
+
+	parsed, err := btcec.ParseDERSignature(sig, btcec.S256())
+	if err != nil {
+		t.Fatalf("agent returned a malformed signature: %v", err)
+	}
+	if !parsed.Verify(digest[:], key.PubKey()) {
+		t.Errorf("signature does not verify")
+	}
+}
+
+func TestAgentUnknownKey(t *testing.T) {
+	key, _ := btcec.NewPrivateKey(btcec.S256())
+	other, _ := btcec.NewPrivateKey(btcec.S256())
+	c, done := startAgent(t, key)
+	defer done()
+
+	digest := sha256.Sum256([]byte("msg"))
+	if _, err := c.Signer(other.PubKey()).Sign(digest[:]); err == nil {
+		t.Errorf("Sign should fail for a key the agent does not hold")
+	}
+
+	if _, err := c.Signer(key.PubKey()).Sign([]byte("short")); err == nil {
+		t.Errorf("Sign should reject digests that are not 32 bytes")
+	}
+}
+
+func TestAgentKeys(t *testing.T) {
+	k1, _ := btcec.NewPrivateKey(btcec.S256())
+	k2, _ := btcec.NewPrivateKey(btcec.S256())
+	c, done := startAgent(t, k1, k2)
+	defer done()
+
+	keys, err := c.Keys()
+	if err != nil {
+		t.Fatalf("Keys returned error: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Errorf("expected 2 keys, got %d", len(keys))
+	}
+
+	if _, err := c.DefaultSigner(); err == nil {
+		t.Errorf("DefaultSigner should fail when the agent holds several keys")
+	}
+}
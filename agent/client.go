@@ -0,0 +1,150 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/mlayne/storj"
+)
+
+// Client talks to an Agent over its socket. It is safe for concurrent use.
+type Client struct {
+	mu   sync.Mutex
+	conn net.Conn
+	dec  *json.Decoder
+	enc  *json.Encoder
+}
+
+// Dial connects to the agent listening on the Unix socket at path.
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		conn: conn,
+		dec:  json.NewDecoder(bufio.NewReader(conn)),
+		enc:  json.NewEncoder(conn),
+	}, nil
+}
+
+// DialEnv connects to the agent named by the STORJ_AUTH_SOCK environment
+// variable.
+func DialEnv() (*Client, error) {
+	path := os.Getenv(SocketEnv)
+	if path == "" {
+		return nil, fmt.Errorf("%s is not set", SocketEnv)
+	}
+
+	return Dial(path)
+}
+
+// Close closes the connection to the agent.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) call(req *request) (*response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.enc.Encode(req); err != nil {
+		return nil, err
+	}
+
+	var resp response
+	if err := c.dec.Decode(&resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("agent: %s", resp.Error)
+	}
+
+	return &resp, nil
+}
+
+// Keys returns the public keys of every key held by the agent.
+func (c *Client) Keys() ([]*btcec.PublicKey, error) {
+	resp, err := c.call(&request{Op: opKeys})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*btcec.PublicKey, 0, len(resp.Keys))
+	for _, k := range resp.Keys {
+		b, err := hex.DecodeString(k)
+		if err != nil {
+			return nil, err
+		}
+		pub, err := btcec.ParsePubKey(b, btcec.S256())
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, pub)
+	}
+
+	return keys, nil
+}
+
+// Signer returns a storj.Signer that signs with the agent's copy of the key
+// matching pub.
+func (c *Client) Signer(pub *btcec.PublicKey) *Signer {
+	return &Signer{client: c, pub: pub}
+}
+
+// DefaultSigner returns a Signer for the agent's only key. It fails if the
+// agent holds no keys or more than one.
+func (c *Client) DefaultSigner() (*Signer, error) {
+	keys, err := c.Keys()
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) != 1 {
+		return nil, fmt.Errorf("agent holds %d keys, expected exactly 1", len(keys))
+	}
+
+	return c.Signer(keys[0]), nil
+}
+
+// Signer implements storj.Signer by forwarding digests to an agent.
+type Signer struct {
+	client *Client
+	pub    *btcec.PublicKey
+}
+
+var _ storj.Signer = (*Signer)(nil)
+
+func (s *Signer) PublicKey() *btcec.PublicKey {
+	return s.pub
+}
+
+func (s *Signer) Sign(digest []byte) ([]byte, error) {
+	resp, err := s.client.call(&request{
+		Op:     opSign,
+		Key:    storj.PubKeyHex(s.pub),
+		Digest: hex.EncodeToString(digest),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := hex.DecodeString(resp.Signature)
+	if err != nil {
+		return nil, errors.New("agent returned a malformed signature")
+	}
+
+	parsed, err := btcec.ParseDERSignature(sig, btcec.S256())
+	if err != nil || !parsed.Verify(digest, s.pub) {
+		return nil, errors.New("agent returned an invalid signature")
+	}
+
+	return sig, nil
+}
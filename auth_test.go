@@ -0,0 +1,71 @@
+package storj
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestBasicAuth(t *testing.T) {
+	setup()
+	defer teardown()
+
+	client.SetBasicAuth("gordon@storj.io", "hunter2")
+	pubKey := hex.EncodeToString(privKey.PubKey().SerializeCompressed())
+
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		email, password, ok := r.BasicAuth()
+		if !ok || email != "gordon@storj.io" || password != HashPassword("hunter2") {
+			t.Errorf("got basic auth %q, %q, %v", email, password, ok)
+		}
+		if r.Header.Get("x-signature") != "" {
+			t.Errorf("basic auth requests should not be signed")
+		}
+
+		if r.Method == "POST" {
+			fmt.Fprintf(w, `{"key": %q, "user": "gordon@storj.io"}`, pubKey)
+			return
+		}
+		fmt.Fprint(w, "[]")
+	})
+
+	ctx := context.Background()
+	if _, err := client.Keys.List(ctx); err != nil {
+		t.Errorf("Keys.List returned error: %v", err)
+	}
+	if err := client.Keys.Register(ctx, pubKey); err != nil {
+		t.Errorf("Keys.Register returned error: %v", err)
+	}
+}
+
+func TestBasicAuthSwitchToKey(t *testing.T) {
+	setup()
+	defer teardown()
+
+	client.SetBasicAuth("gordon@storj.io", "hunter2")
+	enableAuth()
+	defer disableAuth()
+
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		if _, _, ok := r.BasicAuth(); ok {
+			t.Errorf("basic auth should not be sent once AuthKey is set")
+		}
+		if r.Header.Get("x-signature") == "" {
+			t.Errorf(`missing "x-signature" header`)
+		}
+		fmt.Fprint(w, "[]")
+	})
+
+	if _, err := client.Keys.List(context.Background()); err != nil {
+		t.Errorf("Keys.List returned error: %v", err)
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	expected := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	if h := HashPassword(""); h != expected {
+		t.Errorf("HashPassword returned %q, expected %q", h, expected)
+	}
+}
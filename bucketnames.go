@@ -0,0 +1,139 @@
+package storj
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBucketCacheTTL is how long GetByName trusts a bucket listing.
+const DefaultBucketCacheTTL = 5 * time.Minute
+
+// ErrBucketNotFound is returned by GetByName when no bucket has the name.
+var ErrBucketNotFound = errors.New("bucket not found")
+
+// AmbiguousBucketNameError is returned by GetByName when several buckets
+// share a name.
+type AmbiguousBucketNameError struct {
+	Name string
+	IDs  []string
+}
+
+func (e *AmbiguousBucketNameError) Error() string {
+	return fmt.Sprintf("%d buckets are named %q: %s", len(e.IDs), e.Name, strings.Join(e.IDs, ", "))
+}
+
+// bucketNameCache maps bucket names to IDs for each identity a client has
+// authenticated as.
+type bucketNameCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]bucketNameEntry
+}
+
+type bucketNameEntry struct {
+	fetched time.Time
+	ids     map[string][]string
+}
+
+func newBucketNameCache(ttl time.Duration) *bucketNameCache {
+	return &bucketNameCache{ttl: ttl, entries: make(map[string]bucketNameEntry)}
+}
+
+func (c *bucketNameCache) get(identity, name string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[identity]
+	if !ok || time.Since(e.fetched) > c.ttl {
+		return nil, false
+	}
+	return e.ids[name], true
+}
+
+func (c *bucketNameCache) put(identity string, buckets []Bucket) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	ids := make(map[string][]string)
+	for _, b := range buckets {
+		ids[b.Name] = append(ids[b.Name], b.ID)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[identity] = bucketNameEntry{fetched: time.Now(), ids: ids}
+}
+
+func (c *bucketNameCache) invalidate(identity string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, identity)
+}
+
+// identity names the account requests are currently authenticated as.
+func (c *Client) identity() string {
+	if signer := c.signer(); signer != nil {
+		return PubKeyHex(signer.PublicKey())
+	}
+	return "email:" + c.email
+}
+
+func (s *BucketService) invalidateNames() {
+	s.client.bucketNames.invalidate(s.client.identity())
+}
+
+// GetByName returns the bucket with the given name. Names are resolved to IDs
+// through a listing that is cached per authenticated key for the client's
+// bucket cache TTL; buckets created, updated or deleted through the same
+// Client are seen immediately. A name missing from a cached listing, or a
+// cached ID that no longer matches, is looked up again in a fresh listing, so
+// buckets changed by other processes are seen too.
+func (s *BucketService) GetByName(ctx context.Context, name string) (*Bucket, error) {
+	identity := s.client.identity()
+
+	ids, cached := s.client.bucketNames.get(identity, name)
+	if !cached {
+		buckets, err := s.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.client.bucketNames.put(identity, buckets)
+
+		ids = nil
+		for _, b := range buckets {
+			if b.Name == name {
+				ids = append(ids, b.ID)
+			}
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		if cached {
+			// The bucket may have been created by someone else since the
+			// listing was cached.
+			s.client.bucketNames.invalidate(identity)
+			return s.GetByName(ctx, name)
+		}
+		return nil, ErrBucketNotFound
+	case 1:
+	default:
+		return nil, &AmbiguousBucketNameError{Name: name, IDs: ids}
+	}
+
+	bucket, err := s.Get(ctx, ids[0])
+	if cached && (IsNotFound(err) || (err == nil && bucket.Name != name)) {
+		// The bucket was changed by someone else since it was cached.
+		s.client.bucketNames.invalidate(identity)
+		return s.GetByName(ctx, name)
+	}
+
+	return bucket, err
+}
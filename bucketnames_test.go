@@ -0,0 +1,145 @@
+package storj
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func handleBucketNames(t *testing.T) *int {
+	lists := 0
+	mux.HandleFunc("/buckets", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			fmt.Fprint(w, bucketJson)
+			return
+		}
+		lists++
+		fmt.Fprint(w, `[
+  {"id": "a", "name": "unique"},
+  {"id": "b", "name": "dup"},
+  {"id": "c", "name": "dup"}]`)
+	})
+	mux.HandleFunc("/buckets/a", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "GET")
+		fmt.Fprint(w, `{"id": "a", "name": "unique"}`)
+	})
+	return &lists
+}
+
+func TestBucketsGetByName(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	lists := handleBucketNames(t)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		bucket, err := client.Buckets.GetByName(ctx, "unique")
+		if err != nil {
+			t.Fatalf("Buckets.GetByName returned error: %v", err)
+		}
+		if bucket.ID != "a" {
+			t.Errorf("Buckets.GetByName returned bucket %q, expected %q", bucket.ID, "a")
+		}
+	}
+	if *lists != 1 {
+		t.Errorf("expected 1 bucket listing, got %d", *lists)
+	}
+
+	if _, err := client.Buckets.New(ctx, "another", 1, 1); err != nil {
+		t.Fatalf("Buckets.New returned error: %v", err)
+	}
+	if _, err := client.Buckets.GetByName(ctx, "unique"); err != nil {
+		t.Fatalf("Buckets.GetByName returned error: %v", err)
+	}
+	if *lists != 2 {
+		t.Errorf("Buckets.New should invalidate the name cache")
+	}
+}
+
+func TestBucketsGetByNameErrors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	handleBucketNames(t)
+	ctx := context.Background()
+
+	_, err := client.Buckets.GetByName(ctx, "dup")
+	if e, ok := err.(*AmbiguousBucketNameError); !ok || len(e.IDs) != 2 {
+		t.Errorf("expected AmbiguousBucketNameError, got %v", err)
+	}
+
+	_, err = client.Buckets.GetByName(ctx, "missing")
+	if err != ErrBucketNotFound || !IsNotFound(err) {
+		t.Errorf("expected ErrBucketNotFound, got %v", err)
+	}
+}
+
+func TestBucketsGetByNameCreatedElsewhere(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	lists := 0
+	mux.HandleFunc("/buckets", func(w http.ResponseWriter, r *http.Request) {
+		lists++
+		if lists == 1 {
+			fmt.Fprint(w, `[{"id": "a", "name": "unique"}]`)
+			return
+		}
+		fmt.Fprint(w, `[{"id": "a", "name": "unique"}, {"id": "f", "name": "fresh"}]`)
+	})
+	mux.HandleFunc("/buckets/a", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "a", "name": "unique"}`)
+	})
+	mux.HandleFunc("/buckets/f", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "f", "name": "fresh"}`)
+	})
+
+	ctx := context.Background()
+	if _, err := client.Buckets.GetByName(ctx, "unique"); err != nil {
+		t.Fatalf("Buckets.GetByName returned error: %v", err)
+	}
+
+	// "fresh" was created by another process after the listing was cached.
+	bucket, err := client.Buckets.GetByName(ctx, "fresh")
+	if err != nil {
+		t.Fatalf("Buckets.GetByName returned error: %v", err)
+	}
+	if bucket.ID != "f" {
+		t.Errorf("Buckets.GetByName returned bucket %q, expected %q", bucket.ID, "f")
+	}
+	if lists != 2 {
+		t.Errorf("expected 2 bucket listings, got %d", lists)
+	}
+}
+
+func TestBucketsGetByNameNoCache(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var err error
+	client, err = NewClient(WithBaseURL(server.URL), WithAuthKey(privKey), WithBucketCacheTTL(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lists := handleBucketNames(t)
+	for i := 0; i < 2; i++ {
+		if _, err := client.Buckets.GetByName(context.Background(), "unique"); err != nil {
+			t.Fatalf("Buckets.GetByName returned error: %v", err)
+		}
+	}
+	if *lists != 2 {
+		t.Errorf("expected 2 bucket listings without a cache, got %d", *lists)
+	}
+}
@@ -1,11 +1,8 @@
 package storj
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
-	"net/url"
 	"time"
 )
 
@@ -14,18 +11,18 @@ type BucketService struct {
 }
 
 type Bucket struct {
-	ID       string    `json:"id"`
-	Name     string    `json:"name"`
-	User     string    `json:"user"`
-	PubKeys  []string  `json:"pubkeys"`
-	Status   string    `json:"status"`
-	Created  time.Time `json:"created"`
-	Storage  int       `json:"storage"`
-	Transfer int       `json:"transfer"`
+	ID       string       `json:"id"`
+	Name     string       `json:"name"`
+	User     string       `json:"user"`
+	PubKeys  []string     `json:"pubkeys"`
+	Status   BucketStatus `json:"status"`
+	Created  time.Time    `json:"created"`
+	Storage  int          `json:"storage"`
+	Transfer int          `json:"transfer"`
 }
 
-func (s *BucketService) List() ([]Bucket, error) {
-	req, err := s.client.newSignedRequest("GET", "/buckets")
+func (s *BucketService) List(ctx context.Context) ([]Bucket, error) {
+	req, err := s.client.newSignedRequest(ctx, "GET", "/buckets")
 	if err != nil {
 		return nil, err
 	}
@@ -39,42 +36,22 @@ func (s *BucketService) List() ([]Bucket, error) {
 	return buckets, nil
 }
 
-func (s *BucketService) New(name string, storage, transfer int) (*Bucket, error) {
-	nonce, err := s.client.generateNonce()
-	if err != nil {
-		return nil, err
-	}
-
+func (s *BucketService) New(ctx context.Context, name string, storage, transfer int) (*Bucket, error) {
 	b := struct {
 		Name     string `json:"name"`
 		Storage  int    `json:"storage"`
 		Transfer int    `json:"transfer"`
-		Nonce    string `json:"__nonce"`
 	}{
 		name,
 		storage,
 		transfer,
-		nonce,
-	}
-
-	j, err := json.Marshal(&b)
-	if err != nil {
-		return nil, err
 	}
 
-	rel, _ := url.Parse("/buckets")
-	url := s.client.BaseURL.ResolveReference(rel)
-	req, err := http.NewRequest("POST", url.String(), bytes.NewBuffer(j))
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	msg := fmt.Sprintf("POST\n/buckets\n%s", j)
-	err = s.client.signRequest(req, msg)
+	req, err := s.client.newSignedBodyRequest(ctx, "POST", "/buckets", &b)
 	if err != nil {
 		return nil, err
 	}
+	defer s.invalidateNames()
 
 	var bucket Bucket
 	_, err = s.client.Do(req, &bucket)
@@ -85,8 +62,8 @@ func (s *BucketService) New(name string, storage, transfer int) (*Bucket, error)
 	return &bucket, nil
 }
 
-func (s *BucketService) Get(bucketID string) (*Bucket, error) {
-	req, err := s.client.newSignedRequest("GET", fmt.Sprintf("/buckets/%s", bucketID))
+func (s *BucketService) Get(ctx context.Context, bucketID string) (*Bucket, error) {
+	req, err := s.client.newSignedRequest(ctx, "GET", fmt.Sprintf("/buckets/%s", bucketID))
 	if err != nil {
 		return nil, err
 	}
@@ -100,19 +77,53 @@ func (s *BucketService) Get(bucketID string) (*Bucket, error) {
 	return &bucket, nil
 }
 
-func (s *BucketService) Delete(bucketID string) error {
-	req, err := s.client.newSignedRequest("DELETE", fmt.Sprintf("/buckets/%s", bucketID))
+func (s *BucketService) Delete(ctx context.Context, bucketID string) error {
+	req, err := s.client.newSignedRequest(ctx, "DELETE", fmt.Sprintf("/buckets/%s", bucketID))
 	if err != nil {
 		return err
 	}
+	defer s.invalidateNames()
 
-	resp, err := s.client.client.Do(req)
+	_, err = s.client.Do(req, nil)
+	return err
+}
+
+// BucketUpdate lists the changes to make in BucketService.Update. Nil fields
+// are left unchanged; a non-nil empty PubKeys removes every public key.
+type BucketUpdate struct {
+	Name     *string
+	PubKeys  []string
+	Storage  *int
+	Transfer *int
+}
+
+func (s *BucketService) Update(ctx context.Context, bucketID string, u BucketUpdate) (*Bucket, error) {
+	b := make(map[string]interface{})
+	if u.Name != nil {
+		b["name"] = *u.Name
+	}
+	if u.PubKeys != nil {
+		b["pubkeys"] = u.PubKeys
+	}
+	if u.Storage != nil {
+		b["storage"] = *u.Storage
+	}
+	if u.Transfer != nil {
+		b["transfer"] = *u.Transfer
+	}
+
+	path := fmt.Sprintf("/buckets/%s", bucketID)
+	req, err := s.client.newSignedBodyRequest(ctx, "PATCH", path, b)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if resp.StatusCode != 204 {
-		return fmt.Errorf("expected status 204, got %d", resp.StatusCode)
+	defer s.invalidateNames()
+
+	var bucket Bucket
+	_, err = s.client.Do(req, &bucket)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return &bucket, nil
 }
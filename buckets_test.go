@@ -1,6 +1,7 @@
 package storj
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -40,7 +41,7 @@ func TestBucketsList(t *testing.T) {
 	setup()
 	defer teardown()
 
-	_, err := client.Buckets.List()
+	_, err := client.Buckets.List(context.Background())
 	if err == nil || err.Error() != "authentication required" {
 		t.Errorf("Buckets.List should require authentication")
 	}
@@ -59,7 +60,7 @@ func TestBucketsList(t *testing.T) {
 		fmt.Fprintf(w, "[%s]", bucketJson)
 	})
 
-	buckets, err := client.Buckets.List()
+	buckets, err := client.Buckets.List(context.Background())
 	if err != nil {
 		t.Errorf("Buckets.List returned error: %v", err)
 	}
@@ -74,7 +75,7 @@ func TestBucketsNew(t *testing.T) {
 	setup()
 	defer teardown()
 
-	_, err := client.Buckets.New("test bucket", 42, 43)
+	_, err := client.Buckets.New(context.Background(), "test bucket", 42, 43)
 	if err == nil || err.Error() != "authentication required" {
 		t.Errorf("Buckets.List should require authentication")
 	}
@@ -104,7 +105,7 @@ func TestBucketsNew(t *testing.T) {
 		fmt.Fprintf(w, bucketJson)
 	})
 
-	bucket, err := client.Buckets.New("test bucket", 42, 43)
+	bucket, err := client.Buckets.New(context.Background(), "test bucket", 42, 43)
 	if err != nil {
 		t.Errorf("Buckets.New returned error: %v", err)
 	}
@@ -118,7 +119,7 @@ func TestBucketsGet(t *testing.T) {
 	setup()
 	defer teardown()
 
-	_, err := client.Buckets.Get("xyz")
+	_, err := client.Buckets.Get(context.Background(), "xyz")
 	if err == nil || err.Error() != "authentication required" {
 		t.Errorf("Buckets.Get should require authentication")
 	}
@@ -137,7 +138,7 @@ func TestBucketsGet(t *testing.T) {
 		fmt.Fprintf(w, bucketJson)
 	})
 
-	bucket, err := client.Buckets.Get("xyz")
+	bucket, err := client.Buckets.Get(context.Background(), "xyz")
 	if err != nil {
 		t.Errorf("Buckets.Get returned error: %v", err)
 	}
@@ -151,7 +152,7 @@ func TestBucketsDelete(t *testing.T) {
 	setup()
 	defer teardown()
 
-	err := client.Buckets.Delete("xyz")
+	err := client.Buckets.Delete(context.Background(), "xyz")
 	if err == nil || err.Error() != "authentication required" {
 		t.Errorf("Buckets.Delete should require authentication")
 	}
@@ -170,8 +171,61 @@ func TestBucketsDelete(t *testing.T) {
 		w.WriteHeader(204)
 	})
 
-	err = client.Buckets.Delete("xyz")
+	err = client.Buckets.Delete(context.Background(), "xyz")
 	if err != nil {
 		t.Errorf("Buckets.Delete returned error: %v", err)
 	}
 }
+
+func TestBucketsUpdate(t *testing.T) {
+	setup()
+	defer teardown()
+
+	name := "renamed"
+	update := BucketUpdate{Name: &name, PubKeys: []string{}}
+
+	_, err := client.Buckets.Update(context.Background(), "xyz", update)
+	if err == nil || err.Error() != "authentication required" {
+		t.Errorf("Buckets.Update should require authentication")
+	}
+
+	enableAuth()
+	defer disableAuth()
+
+	pubKey := hex.EncodeToString(privKey.PubKey().SerializeCompressed())
+
+	mux.HandleFunc("/buckets/xyz", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "PATCH")
+		assertHeader(t, r, "x-pubkey", pubKey)
+		assertHeader(t, r, "Content-Type", "application/json")
+		if r.Header.Get("x-signature") == "" {
+			t.Errorf(`missing "x-signature" header`)
+		}
+		var sent map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Errorf("received bad JSON")
+		}
+		if sent["name"] != "renamed" {
+			t.Errorf("expected name %q, got %v", "renamed", sent["name"])
+		}
+		if pubkeys, ok := sent["pubkeys"].([]interface{}); !ok || len(pubkeys) != 0 {
+			t.Errorf("expected empty pubkeys, got %v", sent["pubkeys"])
+		}
+		if _, ok := sent["storage"]; ok {
+			t.Errorf("unchanged storage should not be sent")
+		}
+		if _, ok := sent["__nonce"]; !ok {
+			t.Errorf("request did not contain a nonce")
+		}
+		fmt.Fprintf(w, bucketJson)
+	})
+
+	bucket, err := client.Buckets.Update(context.Background(), "xyz", update)
+	if err != nil {
+		t.Errorf("Buckets.Update returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(bucket, &exBucket) {
+		t.Errorf("Buckets.Update returned %+v, expected %+v", bucket, exBucket)
+	}
+}
@@ -0,0 +1,58 @@
+package storj
+
+import (
+	"context"
+	"time"
+)
+
+// BucketStatus is the state the Bridge reports for a bucket. Values the
+// library does not know about are kept verbatim; use Known to tell them
+// apart.
+type BucketStatus string
+
+const (
+	BucketStatusActive   BucketStatus = "Active"
+	BucketStatusInactive BucketStatus = "Inactive"
+
+	// BucketStatusUnknown is the zero value, used when the Bridge does not
+	// report a status.
+	BucketStatusUnknown BucketStatus = ""
+)
+
+// Known reports whether s is one of the statuses defined by this package.
+func (s BucketStatus) Known() bool {
+	return s == BucketStatusActive || s == BucketStatusInactive
+}
+
+// statusPollBackoff is the schedule WaitForStatus polls on.
+var statusPollBackoff = RetryPolicy{
+	BaseDelay: 250 * time.Millisecond,
+	MaxDelay:  5 * time.Second,
+	Jitter:    0.2,
+}
+
+// WaitForStatus polls a bucket with exponential backoff until it has the
+// given status or ctx is done. A bucket that is not found yet is polled
+// again, since a freshly created bucket may take a moment to appear.
+func (s *BucketService) WaitForStatus(ctx context.Context, bucketID string, status BucketStatus) (*Bucket, error) {
+	for attempt := 1; ; attempt++ {
+		bucket, err := s.Get(ctx, bucketID)
+		if err == nil && bucket.Status == status {
+			return bucket, nil
+		}
+		if err != nil && !IsNotFound(err) {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+
+		timer := time.NewTimer(statusPollBackoff.delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package storj
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBucketStatusKnown(t *testing.T) {
+	if !BucketStatusActive.Known() || !BucketStatusInactive.Known() {
+		t.Errorf("defined statuses should be known")
+	}
+	if BucketStatus("Frozen").Known() || BucketStatusUnknown.Known() {
+		t.Errorf("undefined statuses should not be known")
+	}
+}
+
+func TestBucketsWaitForStatus(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	saved := statusPollBackoff
+	statusPollBackoff = RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	defer func() { statusPollBackoff = saved }()
+
+	polls := 0
+	mux.HandleFunc("/buckets/xyz", func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		switch polls {
+		case 1:
+			w.WriteHeader(404)
+		case 2:
+			fmt.Fprint(w, `{"id": "xyz", "status": "Provisioning"}`)
+		default:
+			fmt.Fprint(w, `{"id": "xyz", "status": "Active"}`)
+		}
+	})
+
+	bucket, err := client.Buckets.WaitForStatus(context.Background(), "xyz", BucketStatusActive)
+	if err != nil {
+		t.Fatalf("Buckets.WaitForStatus returned error: %v", err)
+	}
+	if bucket.Status != BucketStatusActive || polls != 3 {
+		t.Errorf("got status %q after %d polls", bucket.Status, polls)
+	}
+}
+
+func TestBucketsWaitForStatusTimeout(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	saved := statusPollBackoff
+	statusPollBackoff = RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	defer func() { statusPollBackoff = saved }()
+
+	mux.HandleFunc("/buckets/xyz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "xyz", "status": "Inactive"}`)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Buckets.WaitForStatus(ctx, "xyz", BucketStatusActive)
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
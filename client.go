@@ -1,64 +1,92 @@
 package storj
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 
 	"github.com/btcsuite/btcd/btcec"
 )
 
+const defaultBaseURL = "https://api.storj.io"
+
 type Client struct {
-	client  *http.Client
+	client      *http.Client
+	userAgent   string
+	nonceSource io.Reader
+
+	// email and password are HTTP basic auth credentials, used only when
+	// there is no Signer or AuthKey. password holds the SHA-256 hex digest the Bridge
+	// expects, never the plaintext.
+	email    string
+	password string
+
 	BaseURL *url.URL
 	AuthKey *btcec.PrivateKey
 
+	// Signer signs requests in place of AuthKey when it is set.
+	Signer Signer
+
+	bucketNames *bucketNameCache
+
+	// seed is the root of the file encryption keys, set by WithEncryption,
+	// and encryptionMode how Upload uses them.
+	seed           []byte
+	encryptionMode EncryptionMode
+
+	// RetryPolicy controls how idempotent requests are retried. A nil
+	// policy sends every request exactly once.
+	RetryPolicy *RetryPolicy
+
 	Keys     KeyService
 	Files    FileService
+	Frames   FrameService
 	Tokens   TokenService
 	Buckets  BucketService
 	Contacts ContactService
 }
 
-func NewClient() *Client {
-	baseURL, _ := url.Parse("https://api.storj.io")
+func NewClient(opts ...Option) (*Client, error) {
+	baseURL, _ := url.Parse(defaultBaseURL)
 
-	c := &Client{client: http.DefaultClient, BaseURL: baseURL}
+	c := &Client{
+		client:      http.DefaultClient,
+		nonceSource: rand.Reader,
+		bucketNames: newBucketNameCache(DefaultBucketCacheTTL),
+		BaseURL:     baseURL,
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	if c.encryptionMode != EncryptionCTR && c.seed == nil {
+		return nil, fmt.Errorf("an encryption mode requires WithEncryption")
+	}
 
 	c.Keys = KeyService{client: c}
 	c.Files = FileService{client: c}
+	c.Frames = FrameService{client: c}
 	c.Tokens = TokenService{client: c}
 	c.Buckets = BucketService{client: c}
 	c.Contacts = ContactService{client: c}
 
-	return c
+	return c, nil
 }
 
-func (c *Client) LoadAuthKey(fileName string) error {
-	keyHex, err := ioutil.ReadFile(fileName)
-	if err != nil {
-		return err
+func (c *Client) Do(req *http.Request, into interface{}) (*http.Response, error) {
+	if c.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.userAgent)
 	}
 
-	keyBytes, err := hex.DecodeString(string(keyHex))
-	if err != nil {
-		return err
-	}
-
-	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), keyBytes)
-	c.AuthKey = privKey
-
-	return nil
-}
-
-func (c *Client) Do(req *http.Request, into interface{}) (*http.Response, error) {
-	resp, err := c.client.Do(req)
+	resp, err := c.send(req)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +94,11 @@ func (c *Client) Do(req *http.Request, into interface{}) (*http.Response, error)
 
 	status := resp.StatusCode
 	if status < 200 || status > 299 {
-		return nil, fmt.Errorf("got status code %d", status)
+		return nil, newAPIError(resp)
+	}
+
+	if into == nil {
+		return resp, nil
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(into); err != nil {
@@ -78,7 +110,7 @@ func (c *Client) Do(req *http.Request, into interface{}) (*http.Response, error)
 
 func (c *Client) generateNonce() (string, error) {
 	b := make([]byte, 16)
-	n, err := io.ReadFull(rand.Reader, b)
+	n, err := io.ReadFull(c.nonceSource, b)
 	if n != len(b) || err != nil {
 		return "", err
 	}
@@ -86,68 +118,165 @@ func (c *Client) generateNonce() (string, error) {
 	return hex.EncodeToString(b), nil
 }
 
+func (c *Client) signer() Signer {
+	if c.Signer != nil {
+		return c.Signer
+	}
+	if c.AuthKey != nil {
+		return keySigner{c.AuthKey}
+	}
+	return nil
+}
+
 func (c *Client) Sign(msg []byte) (string, error) {
-	if c.AuthKey == nil {
+	signer := c.signer()
+	if signer == nil {
 		return "", fmt.Errorf("authentication required")
 	}
 
 	sha := sha256.Sum256(msg)
-	sig, err := c.AuthKey.Sign(sha[:])
+	sig, err := signer.Sign(sha[:])
 	if err != nil {
 		return "", err
 	}
 
-	return hex.EncodeToString(sig.Serialize()), nil
+	return hex.EncodeToString(sig), nil
+}
+
+// SetBasicAuth authenticates requests with an email and password instead of a
+// key. Requests are still signed whenever a Signer or AuthKey is set, so a
+// client can register a key with Keys.Register and then switch to it.
+func (c *Client) SetBasicAuth(email, password string) {
+	c.email = email
+	c.password = HashPassword(password)
+}
+
+// HashPassword returns the hex-encoded SHA-256 digest of password, which is
+// what the Bridge expects in place of the plaintext password.
+func HashPassword(password string) string {
+	sha := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sha[:])
 }
 
 func (c *Client) signRequest(r *http.Request, msg string) error {
+	signer := c.signer()
+	if signer == nil && c.email != "" {
+		r.SetBasicAuth(c.email, c.password)
+		return nil
+	}
+
 	sig, err := c.Sign([]byte(msg))
 	if err != nil {
 		return err
 	}
 
-	key := hex.EncodeToString(c.AuthKey.PubKey().SerializeCompressed())
+	key := hex.EncodeToString(signer.PublicKey().SerializeCompressed())
 
 	r.Header.Add("x-pubkey", key)
 	r.Header.Add("x-signature", sig)
 	return nil
 }
 
-func (c *Client) newRequest(method, path string) (*http.Request, error) {
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
 	rel, err := url.Parse(path)
 	if err != nil {
 		return nil, err
 	}
 
 	url := c.BaseURL.ResolveReference(rel)
-	req, err := http.NewRequest(method, url.String(), nil)
+	req, err := http.NewRequest(method, url.String(), body)
 	if err != nil {
 		return nil, err
 	}
 
-	return req, nil
+	return req.WithContext(ctx), nil
 }
 
-func (c *Client) newSignedRequest(method, path string) (*http.Request, error) {
+func (c *Client) newSignedRequest(ctx context.Context, method, path string) (*http.Request, error) {
 	if method != "GET" && method != "DELETE" && method != "OPTIONS" {
 		return nil, fmt.Errorf("bad method")
 	}
 
+	req, err := c.newRequest(ctx, method, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.signNonce(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// newSignedBodyRequest builds a POST, PUT or PATCH request with body encoded
+// as JSON. A fresh __nonce is added to the body, which is signed as a whole.
+func (c *Client) newSignedBodyRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	if method != "POST" && method != "PUT" && method != "PATCH" {
+		return nil, fmt.Errorf("bad method")
+	}
+
 	nonce, err := c.generateNonce()
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := c.newRequest(method, fmt.Sprintf("%s?__nonce=%s", path, nonce))
+	j, err := withNonce(body, nonce)
 	if err != nil {
 		return nil, err
 	}
 
-	msg := fmt.Sprintf("%s\n%s\n__nonce=%s", method, path, nonce)
-	err = c.signRequest(req, msg)
+	req, err := c.newRequest(ctx, method, path, bytes.NewReader(j))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	err = c.signRequest(req, signingMessage(method, req.URL.Path, string(j)))
 	if err != nil {
 		return nil, err
 	}
 
 	return req, nil
 }
+
+// withNonce encodes body, which must encode to a JSON object, with an added
+// __nonce field.
+func withNonce(body interface{}, nonce string) ([]byte, error) {
+	j, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(j, &fields); err != nil {
+		return nil, fmt.Errorf("request body must be a JSON object: %v", err)
+	}
+
+	if fields == nil {
+		return nil, fmt.Errorf("request body must be a JSON object")
+	}
+
+	n, _ := json.Marshal(nonce)
+	fields["__nonce"] = n
+
+	return json.Marshal(fields)
+}
+
+// signNonce sets a fresh __nonce query parameter on req and signs it. It
+// replaces any nonce and signature already present, so it is also used to
+// re-sign a request before it is retried.
+func (c *Client) signNonce(req *http.Request) error {
+	nonce, err := c.generateNonce()
+	if err != nil {
+		return err
+	}
+
+	req.URL.RawQuery = "__nonce=" + nonce
+	req.Header.Del("x-pubkey")
+	req.Header.Del("x-signature")
+
+	msg := signingMessage(req.Method, req.URL.Path, req.URL.RawQuery)
+	return c.signRequest(req, msg)
+}
@@ -0,0 +1,24 @@
+package storj
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestClientContextCanceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/contacts", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request should not have been sent")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.Contacts.List(ctx)
+	if err == nil {
+		t.Errorf("Contacts.List should fail with a canceled context")
+	}
+}
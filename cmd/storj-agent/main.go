@@ -0,0 +1,235 @@
+// Command storj-agent holds Storj auth keys in memory and signs Bridge
+// requests for other processes over a Unix domain socket.
+//
+// Usage:
+//
+//	eval $(storj-agent keyfile [keyfile ...])
+//
+// Key files may be in any format storj.ReadAuthKey accepts. The agent prompts
+// for the passphrase of encrypted key files, then prints a shell snippet
+// exporting STORJ_AUTH_SOCK, which agent.DialEnv uses to find it, and
+// STORJ_AGENT_PID, and detaches from the terminal, leaving a background
+// process serving the socket. With -d it stays in the foreground instead.
+// The agent removes its socket when it receives SIGTERM or SIGINT.
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/mlayne/storj"
+	"github.com/mlayne/storj/agent"
+	"golang.org/x/term"
+)
+
+// daemonEnv marks the background process the agent re-executes itself as.
+// Its value is the socket path. The listening socket is passed as file
+// descriptor 3 and the keys, one hex-encoded key per line, on descriptor 4.
+const daemonEnv = "STORJ_AGENT_DAEMON"
+
+// tempDirEnv tells the background process which directory to remove along
+// with the socket, if the agent created one.
+const tempDirEnv = "STORJ_AGENT_TMPDIR"
+
+var (
+	socketPath = flag.String("socket", "", "path of the socket to listen on (default: a new temporary directory)")
+	foreground = flag.Bool("d", false, "stay in the foreground instead of detaching")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("storj-agent: ")
+
+	if path := os.Getenv(daemonEnv); path != "" {
+		runDaemon(path, os.Getenv(tempDirEnv))
+		return
+	}
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: storj-agent [-d] [-socket path] keyfile...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var keys []*btcec.PrivateKey
+	for _, fileName := range flag.Args() {
+		key, err := readKey(fileName)
+		if err != nil {
+			log.Fatalf("loading %s: %v", fileName, err)
+		}
+		keys = append(keys, key)
+	}
+
+	path, tempDir := *socketPath, ""
+	if path == "" {
+		dir, err := ioutil.TempDir("", "storj-agent-")
+		if err != nil {
+			log.Fatal(err)
+		}
+		path, tempDir = filepath.Join(dir, "agent.sock"), dir
+	}
+
+	// Keep the socket private to this user between creation and chmod.
+	syscall.Umask(0077)
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		cleanup(path, tempDir)
+		log.Fatal(err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		cleanup(path, tempDir)
+		log.Fatal(err)
+	}
+
+	if *foreground {
+		fmt.Printf("%s=%s; export %s;\n", agent.SocketEnv, path, agent.SocketEnv)
+		serve(l.(*net.UnixListener), keys, path, tempDir)
+		return
+	}
+
+	pid, err := startDaemon(l.(*net.UnixListener), keys, path, tempDir)
+	if err != nil {
+		cleanup(path, tempDir)
+		log.Fatal(err)
+	}
+
+	fmt.Printf("%s=%s; export %s;\n", agent.SocketEnv, path, agent.SocketEnv)
+	fmt.Printf("STORJ_AGENT_PID=%d; export STORJ_AGENT_PID;\n", pid)
+}
+
+// startDaemon re-executes the agent in a new session with its standard
+// streams closed, handing it the listener and keys, and returns its pid.
+func startDaemon(l *net.UnixListener, keys []*btcec.PrivateKey, path, tempDir string) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+
+	lf, err := l.File()
+	if err != nil {
+		return 0, err
+	}
+	defer lf.Close()
+
+	// The daemon owns the socket file from now on.
+	l.SetUnlinkOnClose(false)
+	l.Close()
+
+	kr, kw, err := os.Pipe()
+	if err != nil {
+		return 0, err
+	}
+	defer kr.Close()
+
+	cmd := exec.Command(exe)
+	cmd.Env = append(os.Environ(), daemonEnv+"="+path, tempDirEnv+"="+tempDir)
+	cmd.ExtraFiles = []*os.File{lf, kr}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		kw.Close()
+		return 0, err
+	}
+
+	w := bufio.NewWriter(kw)
+	for _, key := range keys {
+		fmt.Fprintln(w, hex.EncodeToString(key.Serialize()))
+	}
+	err = w.Flush()
+	if cerr := kw.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		cmd.Process.Kill()
+		return 0, fmt.Errorf("passing keys to the agent: %v", err)
+	}
+
+	pid := cmd.Process.Pid
+	cmd.Process.Release()
+	return pid, nil
+}
+
+// runDaemon is the background half of the agent.
+func runDaemon(path, tempDir string) {
+	lf := os.NewFile(3, "listener")
+	fl, err := net.FileListener(lf)
+	lf.Close()
+	if err != nil {
+		cleanup(path, tempDir)
+		os.Exit(1)
+	}
+
+	var keys []*btcec.PrivateKey
+	kf := os.NewFile(4, "keys")
+	scanner := bufio.NewScanner(kf)
+	for scanner.Scan() {
+		b, err := hex.DecodeString(scanner.Text())
+		if err != nil {
+			cleanup(path, tempDir)
+			os.Exit(1)
+		}
+		key, _ := btcec.PrivKeyFromBytes(btcec.S256(), b)
+		keys = append(keys, key)
+	}
+	kf.Close()
+	if scanner.Err() != nil {
+		cleanup(path, tempDir)
+		os.Exit(1)
+	}
+
+	serve(fl.(*net.UnixListener), keys, path, tempDir)
+}
+
+// serve runs the agent until SIGTERM or SIGINT, then removes the socket and
+// any temporary directory created for it.
+func serve(l *net.UnixListener, keys []*btcec.PrivateKey, path, tempDir string) {
+	a := agent.New()
+	for _, key := range keys {
+		a.Add(key)
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	signal.Ignore(syscall.SIGHUP)
+	go func() {
+		<-sigs
+		l.Close()
+	}()
+
+	// Serve returns once the listener is closed.
+	a.Serve(l)
+	cleanup(path, tempDir)
+}
+
+func cleanup(path, tempDir string) {
+	os.Remove(path)
+	if tempDir != "" {
+		os.Remove(tempDir)
+	}
+}
+
+func readKey(fileName string) (*btcec.PrivateKey, error) {
+	key, err := storj.ReadAuthKey(fileName)
+	if err != storj.ErrKeyEncrypted {
+		return key, err
+	}
+
+	fmt.Fprintf(os.Stderr, "Passphrase for %s: ", fileName)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+
+	return storj.ReadEncryptedAuthKey(fileName, passphrase)
+}
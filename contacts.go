@@ -1,6 +1,7 @@
 package storj
 
 import (
+	"context"
 	"fmt"
 	"time"
 )
@@ -17,8 +18,8 @@ type Contact struct {
 	Protocol string    `json:"protocol"`
 }
 
-func (s *ContactService) Get(nodeID string) (*Contact, error) {
-	req, err := s.client.newRequest("GET", fmt.Sprintf("/contacts/%s", nodeID))
+func (s *ContactService) Get(ctx context.Context, nodeID string) (*Contact, error) {
+	req, err := s.client.newRequest(ctx, "GET", fmt.Sprintf("/contacts/%s", nodeID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -32,8 +33,8 @@ func (s *ContactService) Get(nodeID string) (*Contact, error) {
 	return &contact, nil
 }
 
-func (s *ContactService) List() ([]Contact, error) {
-	req, err := s.client.newRequest("GET", "/contacts")
+func (s *ContactService) List(ctx context.Context) ([]Contact, error) {
+	req, err := s.client.newRequest(ctx, "GET", "/contacts", nil)
 	if err != nil {
 		return nil, err
 	}
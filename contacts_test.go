@@ -1,6 +1,7 @@
 package storj
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -25,7 +26,7 @@ func TestContactsGet(t *testing.T) {
 		fmt.Fprintf(w, `{"address": "api.storj.io", "port": 8443, "nodeID": "32033d2dc11b877df4b1caefbffba06495ae6b18", "lastSeen": "2016-05-24T15:16:01.139Z", "protocol": "0.7.0"}`)
 	})
 
-	contact, err := client.Contacts.Get(exContact.NodeID)
+	contact, err := client.Contacts.Get(context.Background(), exContact.NodeID)
 	if err != nil {
 		t.Errorf("Contacts.Get returned error: %v", err)
 	}
@@ -44,7 +45,7 @@ func TestContactsList(t *testing.T) {
 		fmt.Fprintf(w, `[{"address": "api.storj.io", "port": 8443, "nodeID": "32033d2dc11b877df4b1caefbffba06495ae6b18", "lastSeen": "2016-05-24T15:16:01.139Z", "protocol": "0.7.0"}]`)
 	})
 
-	contacts, err := client.Contacts.List()
+	contacts, err := client.Contacts.List(context.Background())
 	if err != nil {
 		t.Errorf("Contacts.List returned error: %v", err)
 	}
@@ -0,0 +1,117 @@
+package storj
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultDeleteConcurrency is the number of files DeleteRecursive removes at
+// once when DeleteOptions.Concurrency is not set.
+const DefaultDeleteConcurrency = 4
+
+// DeleteOptions configures BucketService.DeleteRecursive.
+type DeleteOptions struct {
+	// DryRun lists the files that would be deleted without deleting
+	// anything.
+	DryRun bool
+
+	// Concurrency bounds the number of parallel file deletions.
+	Concurrency int
+}
+
+// FileError is a failure to process a single file.
+type FileError struct {
+	File File
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("file %s (%s): %v", e.File.ID, e.File.Name, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// DeleteError is returned by DeleteRecursive when some files could not be
+// deleted. The bucket itself is left in place.
+type DeleteError struct {
+	BucketID string
+	Failed   []FileError
+}
+
+func (e *DeleteError) Error() string {
+	msgs := make([]string, len(e.Failed))
+	for i := range e.Failed {
+		msgs[i] = e.Failed[i].Error()
+	}
+	return fmt.Sprintf("bucket %s: failed to delete %d files: %s", e.BucketID, len(e.Failed), strings.Join(msgs, "; "))
+}
+
+// DeleteRecursive deletes every file in a bucket and then the bucket itself.
+// It returns the files that were deleted, or with DryRun set, the files that
+// would have been. Files that are already gone by the time they are deleted
+// are skipped. If any file cannot be deleted, the rest are still
+// attempted, the bucket is kept, and a *DeleteError lists the failures.
+func (s *BucketService) DeleteRecursive(ctx context.Context, bucketID string, opts DeleteOptions) ([]File, error) {
+	files, err := s.client.Files.List(ctx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return files, nil
+	}
+
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = DefaultDeleteConcurrency
+	}
+
+	var (
+		mu      sync.Mutex
+		deleted []File
+		failed  []FileError
+		wg      sync.WaitGroup
+	)
+
+	queue := make(chan File)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range queue {
+				err := s.client.Files.Delete(ctx, bucketID, f.ID)
+				if IsNotFound(err) {
+					// Someone else deleted it first.
+					continue
+				}
+
+				mu.Lock()
+				if err != nil {
+					failed = append(failed, FileError{File: f, Err: err})
+				} else {
+					deleted = append(deleted, f)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, f := range files {
+		queue <- f
+	}
+	close(queue)
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return deleted, &DeleteError{BucketID: bucketID, Failed: failed}
+	}
+
+	if err := s.Delete(ctx, bucketID); err != nil {
+		return deleted, err
+	}
+
+	return deleted, nil
+}
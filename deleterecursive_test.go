@@ -0,0 +1,161 @@
+package storj
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// handleBucketFiles serves a bucket with the given file IDs, failing to
+// delete any listed in fail.
+func handleBucketFiles(t *testing.T, ids []string, fail map[string]bool) (deleted map[string]bool, bucketDeleted *bool) {
+	var mu sync.Mutex
+	deleted = make(map[string]bool)
+	bucketDeleted = new(bool)
+
+	mux.HandleFunc("/buckets/xyz/files", func(w http.ResponseWriter, r *http.Request) {
+		var files []string
+		for _, id := range ids {
+			files = append(files, fmt.Sprintf(`{"id": %q, "filename": "%s.txt"}`, id, id))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(files, ","))
+	})
+	mux.HandleFunc("/buckets/xyz/files/", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "DELETE")
+		id := strings.TrimPrefix(r.URL.Path, "/buckets/xyz/files/")
+		if fail[id] {
+			w.WriteHeader(500)
+			return
+		}
+
+		mu.Lock()
+		deleted[id] = true
+		mu.Unlock()
+		w.WriteHeader(204)
+	})
+	mux.HandleFunc("/buckets/xyz", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "DELETE")
+		*bucketDeleted = true
+		w.WriteHeader(204)
+	})
+
+	return deleted, bucketDeleted
+}
+
+func TestBucketsDeleteRecursive(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	ids := []string{"f1", "f2", "f3", "f4", "f5"}
+	deleted, bucketDeleted := handleBucketFiles(t, ids, nil)
+
+	files, err := client.Buckets.DeleteRecursive(context.Background(), "xyz", DeleteOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("Buckets.DeleteRecursive returned error: %v", err)
+	}
+
+	if len(files) != len(ids) || len(deleted) != len(ids) {
+		t.Errorf("expected %d files deleted, got %d (server saw %d)", len(ids), len(files), len(deleted))
+	}
+	if !*bucketDeleted {
+		t.Errorf("Buckets.DeleteRecursive did not delete the bucket")
+	}
+}
+
+func TestBucketsDeleteRecursiveDryRun(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	deleted, bucketDeleted := handleBucketFiles(t, []string{"f1", "f2"}, nil)
+
+	files, err := client.Buckets.DeleteRecursive(context.Background(), "xyz", DeleteOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Buckets.DeleteRecursive returned error: %v", err)
+	}
+
+	if len(files) != 2 {
+		t.Errorf("expected 2 files in dry run, got %d", len(files))
+	}
+	if len(deleted) != 0 || *bucketDeleted {
+		t.Errorf("dry run should not delete anything")
+	}
+}
+
+func TestBucketsDeleteRecursiveFailures(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	fail := map[string]bool{"f2": true, "f4": true}
+	deleted, bucketDeleted := handleBucketFiles(t, []string{"f1", "f2", "f3", "f4"}, fail)
+
+	files, err := client.Buckets.DeleteRecursive(context.Background(), "xyz", DeleteOptions{})
+	derr, ok := err.(*DeleteError)
+	if !ok {
+		t.Fatalf("expected *DeleteError, got %v", err)
+	}
+
+	if len(derr.Failed) != 2 {
+		t.Errorf("expected 2 failures, got %d", len(derr.Failed))
+	}
+	for _, fe := range derr.Failed {
+		if !fail[fe.File.ID] {
+			t.Errorf("unexpected failure for file %s", fe.File.ID)
+		}
+	}
+	if len(files) != 2 || len(deleted) != 2 {
+		t.Errorf("expected the other 2 files to be deleted, got %d", len(files))
+	}
+	if *bucketDeleted {
+		t.Errorf("bucket should not be deleted when files remain")
+	}
+}
+
+func TestBucketsDeleteRecursiveAlreadyGone(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	mux.HandleFunc("/buckets/xyz/files", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": "f1", "filename": "f1.txt"}, {"id": "f2", "filename": "f2.txt"}]`)
+	})
+	mux.HandleFunc("/buckets/xyz/files/", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "DELETE")
+		if r.URL.Path == "/buckets/xyz/files/f2" {
+			w.WriteHeader(404)
+			fmt.Fprint(w, `{"error": "File not found"}`)
+			return
+		}
+		w.WriteHeader(204)
+	})
+	bucketDeleted := false
+	mux.HandleFunc("/buckets/xyz", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "DELETE")
+		bucketDeleted = true
+		w.WriteHeader(204)
+	})
+
+	files, err := client.Buckets.DeleteRecursive(context.Background(), "xyz", DeleteOptions{})
+	if err != nil {
+		t.Fatalf("Buckets.DeleteRecursive returned error: %v", err)
+	}
+	if len(files) != 1 || files[0].ID != "f1" {
+		t.Errorf("expected only f1 to be reported deleted, got %+v", files)
+	}
+	if !bucketDeleted {
+		t.Errorf("a file that was already gone should not keep the bucket")
+	}
+}
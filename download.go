@@ -0,0 +1,275 @@
+package storj
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+)
+
+// MaxShardSize is the most data Download accepts from a farmer for a single
+// shard, since each shard is held in memory until its hash is checked. When
+// the shard's pointer gives its size, no more than that is accepted.
+const MaxShardSize = 64 << 20
+
+// pointerPageSize is the number of pointers Download asks the Bridge for at
+// a time. It is a variable so tests can page through a few shards.
+var pointerPageSize = 6
+
+// ErrErasureCoded is returned by Download for files stored with erasure
+// coding, which it cannot reassemble.
+var ErrErasureCoded = errors.New("erasure coded files are not supported")
+
+// ShardError is a failure to fetch a single shard of a file.
+type ShardError struct {
+	Index  int
+	Hash   string
+	NodeID string
+	Err    error
+}
+
+func (e *ShardError) Error() string {
+	if e.Hash == "" {
+		return fmt.Sprintf("shard %d: %v", e.Index, e.Err)
+	}
+	return fmt.Sprintf("shard %d (%s) from %s: %v", e.Index, e.Hash, e.NodeID, e.Err)
+}
+
+func (e *ShardError) Unwrap() error {
+	return e.Err
+}
+
+// ShardIntegrityError is returned when a farmer sends data that does not
+// match the hash of the shard it was asked for.
+type ShardIntegrityError struct {
+	Index  int
+	NodeID string
+
+	// Hash is the hash the Bridge recorded for the shard and Got the hash
+	// of the data the farmer sent.
+	Hash string
+	Got  string
+}
+
+func (e *ShardIntegrityError) Error() string {
+	return fmt.Sprintf("shard %d from %s: hash mismatch: expected %s, got %s", e.Index, e.NodeID, e.Hash, e.Got)
+}
+
+// Download returns the contents of a file. The shards are fetched from their
+// farmers one at a time, in order, as the returned reader is read. Each shard
+// is checked against its hash before any of it is returned, so a farmer
+// sending bad data makes Read fail with a *ShardIntegrityError. Any other
+// failure to fetch a shard is returned from Read as a *ShardError. A shard
+// missing from the Bridge's pointers, or shards that together do not hold
+// the file's size, are also reported as a *ShardError, so a file is never
+// silently truncated. Files stored with erasure coding are refused with
+// ErrErasureCoded. The caller must Close the reader.
+//
+// If the client was created with WithEncryption, the data is decrypted as it
+// is read. A client in EncryptionAEAD mode only accepts EncryptionAEAD files,
+// and Read fails with ErrTampered if the data was changed. A client in
+// EncryptionCTR mode reads files in either mode, but cannot tell an
+// EncryptionAEAD file whose header was altered from a CTR file, so it
+// detects no tampering at all.
+func (s *FileService) Download(ctx context.Context, bucketID, fileID string) (io.ReadCloser, error) {
+	// The file's info gives its size, and for an encrypted file, the index
+	// its key is derived from.
+	f, err := s.Info(ctx, bucketID, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if f.Erasure != nil && f.Erasure.Type != "" {
+		return nil, fmt.Errorf("file %s: %w (%s)", fileID, ErrErasureCoded, f.Erasure.Type)
+	}
+
+	token, err := s.client.Tokens.New(ctx, "PULL", bucketID)
+	if err != nil {
+		return nil, err
+	}
+
+	pointers, err := s.listAllPointers(ctx, bucketID, fileID, token.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	sr := &shardReader{ctx: ctx, client: s.client, pointers: pointers, size: f.Size}
+	if s.client.seed == nil {
+		return sr, nil
+	}
+
+	if _, _, err := fileCipherKeyIV(s.client.seed, bucketID, f); err != nil {
+		return nil, err
+	}
+
+	return &decrypter{
+		seed:        s.client.seed,
+		bucketID:    bucketID,
+		file:        f,
+		requireAEAD: s.client.encryptionMode == EncryptionAEAD,
+		src:         sr,
+	}, nil
+}
+
+// listAllPointers pages through a file's pointers and checks that they cover
+// shards 0 to n-1 exactly once, sorted by index.
+func (s *FileService) listAllPointers(ctx context.Context, bucketID, fileID, token string) ([]FilePointer, error) {
+	var pointers []FilePointer
+	seen := make(map[int]bool)
+	for {
+		page, err := s.ListPointersPage(ctx, bucketID, fileID, token, len(pointers), pointerPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range page {
+			if p.Parity {
+				return nil, fmt.Errorf("file %s: %w (shard %d is a parity shard)", fileID, ErrErasureCoded, p.Index)
+			}
+			if seen[p.Index] {
+				return nil, &ShardError{Index: p.Index, Hash: p.Hash, NodeID: p.Farmer.NodeID, Err: fmt.Errorf("listed twice by the Bridge")}
+			}
+			seen[p.Index] = true
+		}
+		pointers = append(pointers, page...)
+
+		if len(page) < pointerPageSize {
+			break
+		}
+	}
+
+	sort.SliceStable(pointers, func(i, j int) bool {
+		return pointers[i].Index < pointers[j].Index
+	})
+	for i, p := range pointers {
+		if p.Index != i {
+			return nil, &ShardError{Index: i, Err: fmt.Errorf("missing from the Bridge's pointers")}
+		}
+	}
+
+	return pointers, nil
+}
+
+// shardReader reads the shards behind pointers in turn, fetching and
+// verifying each one only once the previous one is exhausted.
+type shardReader struct {
+	ctx      context.Context
+	client   *Client
+	pointers []FilePointer
+
+	// size is the file's size, which the shards must add up to.
+	size int64
+	read int64
+
+	cur *FilePointer
+	buf bytes.Reader
+	err error
+}
+
+func (r *shardReader) Read(p []byte) (int, error) {
+	for r.err == nil {
+		if r.buf.Len() > 0 {
+			return r.buf.Read(p)
+		}
+		if len(r.pointers) == 0 {
+			if r.read != r.size {
+				r.err = &ShardError{Index: r.nextIndex(), Err: fmt.Errorf("shards hold %d bytes, but the file is %d bytes", r.read, r.size)}
+				break
+			}
+			return 0, io.EOF
+		}
+		r.cur = &r.pointers[0]
+		r.pointers = r.pointers[1:]
+
+		data, err := r.fetch()
+		if err != nil {
+			r.err = err
+			break
+		}
+		r.read += int64(len(data))
+		if r.read > r.size {
+			r.err = r.shardError(fmt.Errorf("shards hold more than the file's %d bytes", r.size))
+			break
+		}
+		r.buf.Reset(data)
+	}
+
+	return 0, r.err
+}
+
+// fetch reads the current shard in full and checks its hash.
+func (r *shardReader) fetch() ([]byte, error) {
+	body, err := r.client.pullShard(r.ctx, r.cur)
+	if err != nil {
+		return nil, r.shardError(err)
+	}
+	defer body.Close()
+
+	limit := int64(MaxShardSize)
+	if r.cur.Size > 0 && r.cur.Size < limit {
+		limit = r.cur.Size
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, r.shardError(err)
+	}
+	if int64(len(data)) > limit {
+		return nil, r.shardError(fmt.Errorf("farmer sent more than %d bytes", limit))
+	}
+
+	if got := ShardHash(data); got != r.cur.Hash {
+		return nil, &ShardIntegrityError{Index: r.cur.Index, NodeID: r.cur.Farmer.NodeID, Hash: r.cur.Hash, Got: got}
+	}
+
+	return data, nil
+}
+
+// nextIndex is the index of the shard after the last one read, where a
+// missing shard would have been.
+func (r *shardReader) nextIndex() int {
+	if r.cur == nil {
+		return 0
+	}
+	return r.cur.Index + 1
+}
+
+func (r *shardReader) shardError(err error) error {
+	return &ShardError{Index: r.cur.Index, Hash: r.cur.Hash, NodeID: r.cur.Farmer.NodeID, Err: err}
+}
+
+func (r *shardReader) Close() error {
+	r.pointers = nil
+	r.buf.Reset(nil)
+	if r.err == nil {
+		r.err = fmt.Errorf("read from closed download")
+	}
+	return nil
+}
+
+// pullShard requests a shard's data from the farmer holding it. The caller
+// must close the returned body.
+func (c *Client) pullShard(ctx context.Context, p *FilePointer) (io.ReadCloser, error) {
+	req, err := http.NewRequest("GET", shardURL(p), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("x-storj-node-id", p.Farmer.NodeID)
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, newAPIError(resp)
+	}
+
+	return resp.Body, nil
+}
@@ -0,0 +1,339 @@
+package storj
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// handleDownload serves a file whose shards hold the given data, listing each
+// page of pointers in reverse order. Fetching a shard whose data is empty
+// fails, and the farmer sends corrupt data for any shard listed in corrupt.
+func handleDownload(t *testing.T, shards []string, corrupt map[int]bool) {
+	handleDownloadFile(t, "xyz", "abc", "", shards, corrupt)
+}
+
+// handleDownloadFile is handleDownload for a given file, whose info reports
+// index.
+func handleDownloadFile(t *testing.T, bucketID, fileID, index string, shards []string, corrupt map[int]bool) {
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	mux.HandleFunc("/buckets/"+bucketID+"/files/"+fileID+"/info", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "GET")
+		fmt.Fprintf(w, `{"id": %q, "bucket": %q, "size": %d, "index": %q}`, fileID, bucketID, len(strings.Join(shards, "")), index)
+	})
+
+	mux.HandleFunc("/buckets/"+bucketID+"/tokens", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "POST")
+		fmt.Fprintf(w, `{"token": "pull_token", "bucket": %q, "operation": "PULL"}`, bucketID)
+	})
+	mux.HandleFunc("/buckets/"+bucketID+"/files/"+fileID, func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "GET")
+		assertHeader(t, r, "x-token", "pull_token")
+
+		skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end := len(shards)
+		if limit > 0 && skip+limit < end {
+			end = skip + limit
+		}
+
+		var pointers []string
+		for i := end - 1; i >= skip; i-- {
+			pointers = append(pointers, fmt.Sprintf(
+				`{"index": %d, "hash": %q, "token": "t%d", "operation": "PULL", "farmer": {"address": %q, "port": %s, "nodeID": "node%d"}}`,
+				i, ShardHash([]byte(shards[i])), i, host, port, i))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(pointers, ","))
+	})
+	mux.HandleFunc("/shards/", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "GET")
+
+		var i int
+		fmt.Sscanf(r.URL.Query().Get("token"), "t%d", &i)
+		if hash := strings.TrimPrefix(r.URL.Path, "/shards/"); hash != ShardHash([]byte(shards[i])) {
+			t.Errorf("shard %d fetched as %s", i, hash)
+		}
+		assertHeader(t, r, "x-storj-node-id", fmt.Sprintf("node%d", i))
+
+		if shards[i] == "" {
+			w.WriteHeader(500)
+			fmt.Fprint(w, `{"error": "Shard data not found"}`)
+			return
+		}
+		if corrupt[i] {
+			fmt.Fprint(w, strings.ToUpper(shards[i]))
+			return
+		}
+		fmt.Fprint(w, shards[i])
+	})
+}
+
+func TestFilesDownload(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	defer func(n int) { pointerPageSize = n }(pointerPageSize)
+	pointerPageSize = 2
+
+	handleDownload(t, []string{"0123", "4567", "89"}, nil)
+
+	rc, err := client.Files.Download(context.Background(), "xyz", "abc")
+	if err != nil {
+		t.Fatalf("Files.Download returned error: %v", err)
+	}
+	defer rc.Close()
+
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("reading download returned error: %v", err)
+	}
+	if string(data) != "0123456789" {
+		t.Errorf("downloaded %q, expected %q", data, "0123456789")
+	}
+}
+
+func TestFilesDownloadShardError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	handleDownload(t, []string{"0123", "", "89"}, nil)
+
+	rc, err := client.Files.Download(context.Background(), "xyz", "abc")
+	if err != nil {
+		t.Fatalf("Files.Download returned error: %v", err)
+	}
+	defer rc.Close()
+
+	data, err := ioutil.ReadAll(rc)
+	if string(data) != "0123" {
+		t.Errorf("read %q before the failure, expected %q", data, "0123")
+	}
+
+	var shardErr *ShardError
+	if !errors.As(err, &shardErr) {
+		t.Fatalf("expected *ShardError, got %v", err)
+	}
+	if shardErr.Index != 1 || shardErr.Hash != ShardHash(nil) || shardErr.NodeID != "node1" {
+		t.Errorf("ShardError = %+v", shardErr)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 500 {
+		t.Errorf("expected the farmer's APIError, got %v", shardErr.Err)
+	}
+}
+
+func TestFilesDownloadClose(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	handleDownload(t, []string{"0123", "4567"}, nil)
+
+	rc, err := client.Files.Download(context.Background(), "xyz", "abc")
+	if err != nil {
+		t.Fatalf("Files.Download returned error: %v", err)
+	}
+
+	buf := make([]byte, 2)
+	if _, err := rc.Read(buf); err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if err := rc.Close(); err != nil {
+		t.Errorf("Close returned error: %v", err)
+	}
+	if _, err := rc.Read(buf); err == nil {
+		t.Errorf("Read after Close should fail")
+	}
+}
+
+func TestFilesDownloadIntegrity(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	handleDownload(t, []string{"abcd", "efgh", "ij"}, map[int]bool{1: true})
+
+	rc, err := client.Files.Download(context.Background(), "xyz", "abc")
+	if err != nil {
+		t.Fatalf("Files.Download returned error: %v", err)
+	}
+	defer rc.Close()
+
+	data, err := ioutil.ReadAll(rc)
+	if string(data) != "abcd" {
+		t.Errorf("read %q, expected only the first shard %q", data, "abcd")
+	}
+
+	var integrityErr *ShardIntegrityError
+	if !errors.As(err, &integrityErr) {
+		t.Fatalf("expected *ShardIntegrityError, got %v", err)
+	}
+	expected := ShardIntegrityError{Index: 1, NodeID: "node1", Hash: ShardHash([]byte("efgh")), Got: ShardHash([]byte("EFGH"))}
+	if *integrityErr != expected {
+		t.Errorf("ShardIntegrityError = %+v, expected %+v", *integrityErr, expected)
+	}
+}
+
+func TestFilesDownloadOversizedShard(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	mux.HandleFunc("/buckets/xyz/files/abc/info", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "abc", "size": 4}`)
+	})
+	mux.HandleFunc("/buckets/xyz/tokens", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"token": "pull_token"}`)
+	})
+	mux.HandleFunc("/buckets/xyz/files/abc", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"index": 0, "hash": %q, "size": 4, "token": "t0", "farmer": {"address": %q, "port": %s, "nodeID": "node0"}}]`,
+			ShardHash([]byte("0123")), host, port)
+	})
+	mux.HandleFunc("/shards/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, strings.Repeat("0123", 1000))
+	})
+
+	rc, err := client.Files.Download(context.Background(), "xyz", "abc")
+	if err != nil {
+		t.Fatalf("Files.Download returned error: %v", err)
+	}
+	defer rc.Close()
+
+	data, err := ioutil.ReadAll(rc)
+	if len(data) != 0 {
+		t.Errorf("read %d bytes from an oversized shard", len(data))
+	}
+
+	var shardErr *ShardError
+	if !errors.As(err, &shardErr) || shardErr.Index != 0 || !strings.Contains(err.Error(), "more than 4 bytes") {
+		t.Errorf("expected a *ShardError for the oversized shard, got %v", err)
+	}
+}
+
+// handleIncompleteDownload serves a file of size bytes whose pointers cover
+// only the given shards of "0123", "4567" and "89".
+func handleIncompleteDownload(t *testing.T, size int, listed ...int) {
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	shards := []string{"0123", "4567", "89"}
+
+	mux.HandleFunc("/buckets/xyz/files/abc/info", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": "abc", "size": %d}`, size)
+	})
+	mux.HandleFunc("/buckets/xyz/tokens", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"token": "pull_token"}`)
+	})
+	mux.HandleFunc("/buckets/xyz/files/abc", func(w http.ResponseWriter, r *http.Request) {
+		var pointers []string
+		for _, i := range listed {
+			pointers = append(pointers, fmt.Sprintf(`{"index": %d, "hash": %q, "token": "t%d", "farmer": {"address": %q, "port": %s, "nodeID": "node%d"}}`,
+				i, ShardHash([]byte(shards[i])), i, host, port, i))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(pointers, ","))
+	})
+	mux.HandleFunc("/shards/", func(w http.ResponseWriter, r *http.Request) {
+		var i int
+		fmt.Sscanf(r.URL.Query().Get("token"), "t%d", &i)
+		fmt.Fprint(w, shards[i])
+	})
+}
+
+func TestFilesDownloadMissingPointer(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	handleIncompleteDownload(t, 10, 0, 2)
+
+	_, err := client.Files.Download(context.Background(), "xyz", "abc")
+	var shardErr *ShardError
+	if !errors.As(err, &shardErr) || shardErr.Index != 1 {
+		t.Errorf("expected a *ShardError for shard 1, got %v", err)
+	}
+}
+
+func TestFilesDownloadSizeMismatch(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	// The last shard is missing from the end of the list.
+	handleIncompleteDownload(t, 10, 0, 1)
+
+	rc, err := client.Files.Download(context.Background(), "xyz", "abc")
+	if err != nil {
+		t.Fatalf("Files.Download returned error: %v", err)
+	}
+	defer rc.Close()
+
+	data, err := ioutil.ReadAll(rc)
+	var shardErr *ShardError
+	if !errors.As(err, &shardErr) || shardErr.Index != 2 {
+		t.Errorf("expected a *ShardError for shard 2, got %q, %v", data, err)
+	}
+}
+
+func TestFilesDownloadErasureCoded(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	mux.HandleFunc("/buckets/xyz/files/abc/info", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "abc", "size": 10, "erasure": {"type": "reedsolomon"}}`)
+	})
+
+	_, err := client.Files.Download(context.Background(), "xyz", "abc")
+	if !errors.Is(err, ErrErasureCoded) {
+		t.Errorf("expected ErrErasureCoded, got %v", err)
+	}
+}
+
+func TestFilesDownloadParityPointer(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	mux.HandleFunc("/buckets/xyz/files/abc/info", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "abc", "size": 4}`)
+	})
+	mux.HandleFunc("/buckets/xyz/tokens", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"token": "pull_token"}`)
+	})
+	mux.HandleFunc("/buckets/xyz/files/abc", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"index": 0, "hash": "a"}, {"index": 1, "hash": "b", "parity": true}]`)
+	})
+
+	_, err := client.Files.Download(context.Background(), "xyz", "abc")
+	if !errors.Is(err, ErrErasureCoded) {
+		t.Errorf("expected ErrErasureCoded, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package storj
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// Keys are derived following libstorj's scheme, and the bucket and file keys
+// match its test vectors. The cipher step has not been checked against files
+// written by another client. Every key is handled as a hex string, as
+// libstorj does:
+//
+//	seed       = PBKDF2-HMAC-SHA512(mnemonic, "mnemonic", 2048 rounds, 64 bytes)
+//	bucket key = SHA-512(seed || bucket ID)[:32]
+//	file key   = SHA-512(bucket key || key ID)[:32]
+//
+// where IDs are hex-decoded before hashing. The key ID is the file's index, a
+// random 32-byte value stored with the file entry. Upload always sets an
+// index, and for such files:
+//
+//	AES-256 key = SHA-256(hex(file key))
+//	CTR IV      = index[:16]
+//
+// Files written by older clients have no index. Their key ID is the file ID
+// and:
+//
+//	salt        = RIPEMD-160(file ID as text)
+//	AES-256 key = PBKDF2-HMAC-SHA512(hex(file key), salt, 1 round, 32 bytes)
+//	CTR IV      = salt[:16]
+
+const (
+	seedIterations = 2048
+	seedSize       = 64
+
+	// deterministicKeySize is the length of derived bucket and file keys.
+	deterministicKeySize = 32
+
+	// fileIDSize is the length in bytes of a file ID.
+	fileIDSize = 12
+
+	// indexSize is the length in bytes of a file's index.
+	indexSize = 32
+)
+
+// MnemonicToSeed returns the BIP39 seed for a mnemonic with an empty
+// passphrase, the root from which every bucket and file key is derived. The
+// mnemonic is not checked against the BIP39 word list.
+func MnemonicToSeed(mnemonic string) []byte {
+	return pbkdf2.Key([]byte(mnemonic), []byte("mnemonic"), seedIterations, seedSize, sha512.New)
+}
+
+// FileID returns the ID the Bridge gives a file called name in a bucket:
+// the first 12 bytes of RIPEMD-160(SHA-256(bucketID + name)), hex-encoded.
+func FileID(bucketID, name string) string {
+	return hex.EncodeToString(rmd160sha256([]byte(bucketID + name))[:fileIDSize])
+}
+
+// deterministicKey derives a child key from key and a hex-encoded ID.
+func deterministicKey(key []byte, id string) ([]byte, error) {
+	b, err := hex.DecodeString(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID %q: %v", id, err)
+	}
+
+	sha := sha512.Sum512(append(append([]byte{}, key...), b...))
+	return sha[:deterministicKeySize], nil
+}
+
+// bucketKey derives the key for a bucket from the mnemonic seed.
+func bucketKey(seed []byte, bucketID string) ([]byte, error) {
+	return deterministicKey(seed, bucketID)
+}
+
+// fileKey derives the key for a file from the mnemonic seed. keyID is the
+// file's index, or for files without one, its ID.
+func fileKey(seed []byte, bucketID, keyID string) ([]byte, error) {
+	bk, err := bucketKey(seed, bucketID)
+	if err != nil {
+		return nil, err
+	}
+	return deterministicKey(bk, keyID)
+}
+
+// newIndex returns a random hex-encoded file index.
+func newIndex() (string, error) {
+	b := make([]byte, indexSize)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// keyID is the ID a file's key is derived from.
+func keyID(f *File) string {
+	if f.Index != "" {
+		return f.Index
+	}
+	return f.ID
+}
+
+// fileCipherKeyIV returns the AES-256 key and CTR IV for a file.
+func fileCipherKeyIV(seed []byte, bucketID string, f *File) (key, iv []byte, err error) {
+	fk, err := fileKey(seed, bucketID, keyID(f))
+	if err != nil {
+		return nil, nil, err
+	}
+	pass := []byte(hex.EncodeToString(fk))
+
+	if f.Index != "" {
+		index, _ := hex.DecodeString(f.Index)
+		if len(index) < aes.BlockSize {
+			return nil, nil, fmt.Errorf("invalid file index %q", f.Index)
+		}
+		sha := sha256.Sum256(pass)
+		return sha[:], index[:aes.BlockSize], nil
+	}
+
+	rmd := ripemd160.New()
+	rmd.Write([]byte(f.ID))
+	salt := rmd.Sum(nil)
+
+	return pbkdf2.Key(pass, salt, 1, 32, sha512.New), salt[:aes.BlockSize], nil
+}
+
+// fileStream returns the AES-256-CTR keystream for a file. The same stream
+// encrypts and decrypts.
+func fileStream(seed []byte, bucketID string, f *File) (cipher.Stream, error) {
+	key, iv, err := fileCipherKeyIV(seed, bucketID, f)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewCTR(block, iv), nil
+}
+
+// validMnemonic reports whether mnemonic has a BIP39 word count.
+func validMnemonic(mnemonic string) bool {
+	switch len(strings.Fields(mnemonic)) {
+	case 12, 15, 18, 21, 24:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package storj
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io/ioutil"
+	"testing"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestMnemonicToSeed(t *testing.T) {
+	// BIP39 test vector for an empty passphrase.
+	expected := "5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc19a5ac40b389cd370d086206dec8aa6c43daea6690f20ad3d8d48b2d2ce9e38e4"
+
+	if seed := hex.EncodeToString(MnemonicToSeed(testMnemonic)); seed != expected {
+		t.Errorf("MnemonicToSeed returned %s, expected %s", seed, expected)
+	}
+}
+
+func TestFileID(t *testing.T) {
+	id := FileID("368be0816766b28fd5f43af5", "hello.txt")
+
+	if expected := "0939e9202d0359580e0be49a"; id != expected {
+		t.Errorf("FileID returned %s, expected %s", id, expected)
+	}
+	if id == FileID("368be0816766b28fd5f43af5", "hello2.txt") {
+		t.Errorf("FileID returned the same ID for different names")
+	}
+}
+
+// Known-answer values for testMnemonic. The bucket and file keys are those
+// of libstorj's key derivation tests; the cipher keys, IVs and ciphertexts
+// were computed independently of this package.
+const (
+	testBucketID = "0123456789ab0123456789ab"
+	testIndex    = "150589c9593bbebc0e795d8c4fa97304b42c110d9f0095abfac644763beca66e"
+	testFileID   = "998960317b6725a3f8080c2b"
+)
+
+func TestBucketKey(t *testing.T) {
+	key, err := bucketKey(MnemonicToSeed(testMnemonic), testBucketID)
+	if err != nil {
+		t.Fatalf("bucketKey returned error: %v", err)
+	}
+	if got, expected := hex.EncodeToString(key), "b2464469e364834ad21e24c64f637c39083af5067693605c84e259447644f6f6"; got != expected {
+		t.Errorf("bucketKey returned %s, expected %s", got, expected)
+	}
+
+	if _, err := bucketKey(MnemonicToSeed(testMnemonic), "not hex"); err == nil {
+		t.Errorf("bucketKey should reject a non-hex bucket ID")
+	}
+}
+
+func TestFileKey(t *testing.T) {
+	seed := MnemonicToSeed(testMnemonic)
+
+	for _, tt := range []struct{ keyID, expected string }{
+		{testIndex, "bb3552fc2e16d24a147af4b2d163e3164e6dbd04bbc45fc1c3eab69f384337e9"},
+		{testFileID, "c5602e22b5339791f60f1bb4ab9807a6bf94803dc0860b1c5ea1a41de0e680aa"},
+	} {
+		key, err := fileKey(seed, testBucketID, tt.keyID)
+		if err != nil {
+			t.Fatalf("fileKey(%s) returned error: %v", tt.keyID, err)
+		}
+		if got := hex.EncodeToString(key); got != tt.expected {
+			t.Errorf("fileKey(%s) returned %s, expected %s", tt.keyID, got, tt.expected)
+		}
+	}
+}
+
+func TestFileCipherKeyIV(t *testing.T) {
+	seed := MnemonicToSeed(testMnemonic)
+	plaintext := []byte("hello storj")
+
+	tests := []struct {
+		name           string
+		file           *File
+		key, iv, ctext string
+	}{
+		{
+			"index",
+			&File{ID: testFileID, Index: testIndex},
+			"9fffd2b4692a4e97e3b17dc0493b1c0a2022def9861d996787d41d3be915b302",
+			"150589c9593bbebc0e795d8c4fa97304",
+			"e9f99ff23bb1ce1d5998cd",
+		},
+		{
+			"legacy",
+			&File{ID: testFileID},
+			"954440ca47fb0aa3f64814380c1dd6ba8023f6c0198c313f20b82a16698eda36",
+			"46dbf787a2075dc12c7bbceacb738152",
+			"d6dc9c73909957c0d24c71",
+		},
+	}
+
+	for _, tt := range tests {
+		key, iv, err := fileCipherKeyIV(seed, testBucketID, tt.file)
+		if err != nil {
+			t.Fatalf("%s: fileCipherKeyIV returned error: %v", tt.name, err)
+		}
+		if got := hex.EncodeToString(key); got != tt.key {
+			t.Errorf("%s: key is %s, expected %s", tt.name, got, tt.key)
+		}
+		if got := hex.EncodeToString(iv); got != tt.iv {
+			t.Errorf("%s: IV is %s, expected %s", tt.name, got, tt.iv)
+		}
+
+		stream, _ := fileStream(seed, testBucketID, tt.file)
+		ciphertext := make([]byte, len(plaintext))
+		stream.XORKeyStream(ciphertext, plaintext)
+		if got := hex.EncodeToString(ciphertext); got != tt.ctext {
+			t.Errorf("%s: ciphertext is %s, expected %s", tt.name, got, tt.ctext)
+		}
+	}
+
+	if _, _, err := fileCipherKeyIV(seed, testBucketID, &File{ID: testFileID, Index: "0a0b"}); err == nil {
+		t.Errorf("fileCipherKeyIV should reject an index shorter than the IV")
+	}
+}
+
+func TestWithEncryption(t *testing.T) {
+	c, err := NewClient(WithEncryption("  " + testMnemonic + "\n"))
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+	if !bytes.Equal(c.seed, MnemonicToSeed(testMnemonic)) {
+		t.Errorf("WithEncryption did not normalize the mnemonic's whitespace")
+	}
+
+	if _, err := NewClient(WithEncryption("too short")); err == nil {
+		t.Errorf("WithEncryption should reject a mnemonic with the wrong word count")
+	}
+}
+
+func TestFilesUploadEncrypted(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	client.seed = MnemonicToSeed(testMnemonic)
+	shards, created := handleUpload(t)
+
+	data := []byte("0123456789")
+	_, err := client.Files.Upload(context.Background(), "0123456789abcdef01234567", "digits.txt", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Files.Upload returned error: %v", err)
+	}
+
+	index := (*created)["index"]
+	if b, _ := hex.DecodeString(index); len(b) != indexSize {
+		t.Fatalf("Files.Upload sent index %q, expected %d random bytes", index, indexSize)
+	}
+
+	if len(shards) != 1 {
+		t.Fatalf("farmer received %d shards, expected 1", len(shards))
+	}
+	for _, got := range shards {
+		if bytes.Equal(got, data) {
+			t.Errorf("farmer received plaintext")
+		}
+
+		stream, _ := fileStream(client.seed, "0123456789abcdef01234567", &File{Index: index})
+		stream.XORKeyStream(got, got)
+		if !bytes.Equal(got, data) {
+			t.Errorf("shard decrypts to %q, expected %q", got, data)
+		}
+	}
+}
+
+func TestFilesDownloadEncrypted(t *testing.T) {
+	testFilesDownloadEncrypted(t, testIndex)
+}
+
+func TestFilesDownloadEncryptedLegacy(t *testing.T) {
+	testFilesDownloadEncrypted(t, "")
+}
+
+// testFilesDownloadEncrypted downloads a CTR-encrypted file with the given
+// index, or a file from an older client if index is empty.
+func testFilesDownloadEncrypted(t *testing.T, index string) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	client.seed = MnemonicToSeed(testMnemonic)
+	bucketID, fileID := "0123456789abcdef01234567", "abcdef0123456789abcdef01"
+
+	plaintext := []byte("0123456789")
+	ciphertext := make([]byte, len(plaintext))
+	stream, _ := fileStream(client.seed, bucketID, &File{ID: fileID, Index: index})
+	stream.XORKeyStream(ciphertext, plaintext)
+
+	handleDownloadFile(t, bucketID, fileID, index, []string{string(ciphertext[:4]), string(ciphertext[4:])}, nil)
+
+	rc, err := client.Files.Download(context.Background(), bucketID, fileID)
+	if err != nil {
+		t.Fatalf("Files.Download returned error: %v", err)
+	}
+	defer rc.Close()
+
+	got, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("reading download returned error: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("downloaded %q, expected %q", got, plaintext)
+	}
+}
@@ -0,0 +1,100 @@
+package storj
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// StatusTransferRateExceeded is the non-standard status code the Bridge uses
+// when an account has exhausted its storage or transfer quota.
+const StatusTransferRateExceeded = 420
+
+// maxErrorBodySize bounds how much of an error response is read into memory.
+const maxErrorBodySize = 64 << 10
+
+// APIError is returned for any non-2xx response from the Bridge.
+type APIError struct {
+	StatusCode int
+	Message    string
+	Method     string
+	Path       string
+	Header     http.Header
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s %s: got status code %d", e.Method, e.Path, e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s: got status code %d: %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// newAPIError builds an APIError from a failed response, pulling the message
+// out of the Bridge's {"error": "..."} body when there is one.
+func newAPIError(resp *http.Response) *APIError {
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+	}
+	if resp.Request != nil {
+		e.Method = resp.Request.Method
+		e.Path = resp.Request.URL.Path
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil || len(body) == 0 {
+		return e
+	}
+
+	var b struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &b); err == nil && b.Error != "" {
+		e.Message = b.Error
+	} else {
+		e.Message = strings.TrimSpace(string(body))
+	}
+
+	return e
+}
+
+func hasStatus(err error, codes ...int) bool {
+	var e *APIError
+	if !errors.As(err, &e) {
+		return false
+	}
+	for _, code := range codes {
+		if e.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNotFound reports whether err is an APIError for a missing resource, or
+// ErrBucketNotFound.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrBucketNotFound) || hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is an APIError caused by missing or
+// rejected credentials.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized, http.StatusForbidden)
+}
+
+// IsRateLimited reports whether err is an APIError caused by sending too many
+// requests.
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+// IsQuotaExceeded reports whether err is an APIError caused by exceeding the
+// account's storage or transfer limits.
+func IsQuotaExceeded(err error) bool {
+	return hasStatus(err, StatusTransferRateExceeded)
+}
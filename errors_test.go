@@ -0,0 +1,66 @@
+package storj
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	mux.HandleFunc("/buckets/xyz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(404)
+		fmt.Fprint(w, `{"error": "Bucket not found"}`)
+	})
+
+	_, err := client.Buckets.Get(context.Background(), "xyz")
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("Buckets.Get returned %T, expected *APIError", err)
+	}
+
+	if apiErr.StatusCode != 404 || apiErr.Message != "Bucket not found" {
+		t.Errorf("got status %d and message %q", apiErr.StatusCode, apiErr.Message)
+	}
+	if apiErr.Method != "GET" || apiErr.Path != "/buckets/xyz" {
+		t.Errorf("got method %q and path %q", apiErr.Method, apiErr.Path)
+	}
+	if apiErr.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("APIError is missing response headers")
+	}
+
+	if !IsNotFound(err) {
+		t.Errorf("IsNotFound should be true for a 404")
+	}
+	if IsUnauthorized(err) || IsRateLimited(err) || IsQuotaExceeded(err) {
+		t.Errorf("a 404 should not match other error kinds")
+	}
+}
+
+func TestAPIErrorDelete(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	mux.HandleFunc("/buckets/abc/files/xyz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(StatusTransferRateExceeded)
+		fmt.Fprint(w, "slow down")
+	})
+
+	err := client.Files.Delete(context.Background(), "abc", "xyz")
+	if !IsQuotaExceeded(err) {
+		t.Errorf("IsQuotaExceeded should be true, got %v", err)
+	}
+	if apiErr, ok := err.(*APIError); !ok || apiErr.Message != "slow down" {
+		t.Errorf("expected non-JSON body to be used as message, got %v", err)
+	}
+}
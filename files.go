@@ -1,8 +1,8 @@
 package storj
 
 import (
+	"context"
 	"fmt"
-	"net/url"
 )
 
 type FileService struct {
@@ -16,10 +16,23 @@ type File struct {
 	Name     string `json:"filename"`
 	Size     int64  `json:"size"`
 	Frame    string `json:"frame"`
+
+	// Index is the random value the file's encryption key is derived from.
+	// Files uploaded by older clients have none.
+	Index string `json:"index,omitempty"`
+
+	// Erasure is set for files stored with erasure coding, whose shards
+	// include parity shards.
+	Erasure *Erasure `json:"erasure,omitempty"`
+}
+
+// Erasure describes the erasure coding of a file.
+type Erasure struct {
+	Type string `json:"type"`
 }
 
-func (s *FileService) List(bucketID string) ([]File, error) {
-	req, err := s.client.newSignedRequest("GET", fmt.Sprintf("/buckets/%s/files", bucketID))
+func (s *FileService) List(ctx context.Context, bucketID string) ([]File, error) {
+	req, err := s.client.newSignedRequest(ctx, "GET", fmt.Sprintf("/buckets/%s/files", bucketID))
 	if err != nil {
 		return nil, err
 	}
@@ -33,22 +46,31 @@ func (s *FileService) List(bucketID string) ([]File, error) {
 	return files, nil
 }
 
-func (s *FileService) Delete(bucketID, fileID string) error {
-	path := fmt.Sprintf("/buckets/%s/files/%s", bucketID, fileID)
-	req, err := s.client.newSignedRequest("DELETE", path)
+// Info returns a single file's details.
+func (s *FileService) Info(ctx context.Context, bucketID, fileID string) (*File, error) {
+	req, err := s.client.newSignedRequest(ctx, "GET", fmt.Sprintf("/buckets/%s/files/%s/info", bucketID, fileID))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	resp, err := s.client.client.Do(req)
+	var f File
+	_, err = s.client.Do(req, &f)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if resp.StatusCode != 204 {
-		return fmt.Errorf("expected status 204, got %d", resp.StatusCode)
+
+	return &f, nil
+}
+
+func (s *FileService) Delete(ctx context.Context, bucketID, fileID string) error {
+	path := fmt.Sprintf("/buckets/%s/files/%s", bucketID, fileID)
+	req, err := s.client.newSignedRequest(ctx, "DELETE", path)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	_, err = s.client.Do(req, nil)
+	return err
 }
 
 // TODO Reuse Contact here. json.Unmarshal doesn't handle the node-style timestamp that
@@ -63,16 +85,39 @@ type Farmer struct {
 }
 
 type FilePointer struct {
+	Index     int    `json:"index"`
 	Hash      string `json:"hash"`
+	Size      int64  `json:"size"`
 	Token     string `json:"token"`
 	Operation string `json:"operation"`
 	Farmer    Farmer `json:"farmer"`
+
+	// Parity is set for the parity shards of an erasure coded file.
+	Parity bool `json:"parity,omitempty"`
+}
+
+func (s *FileService) ListPointers(ctx context.Context, bucketID, fileID, token string) ([]FilePointer, error) {
+	req, err := s.client.newRequest(ctx, "GET", fmt.Sprintf("/buckets/%s/files/%s", bucketID, fileID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("x-token", token)
+
+	var fps []FilePointer
+	_, err = s.client.Do(req, &fps)
+	if err != nil {
+		return nil, err
+	}
+
+	return fps, nil
 }
 
-func (s *FileService) ListPointers(bucketID, fileID, token string) ([]FilePointer, error) {
-	rel, _ := url.Parse(fmt.Sprintf("/buckets/%s/files/%s", bucketID, fileID))
-	url := s.client.BaseURL.ResolveReference(rel)
-	req, err := s.client.newRequest("GET", url.String())
+// ListPointersPage is like ListPointers, but returns at most limit pointers
+// starting from the skip'th shard.
+func (s *FileService) ListPointersPage(ctx context.Context, bucketID, fileID, token string, skip, limit int) ([]FilePointer, error) {
+	path := fmt.Sprintf("/buckets/%s/files/%s?skip=%d&limit=%d", bucketID, fileID, skip, limit)
+	req, err := s.client.newRequest(ctx, "GET", path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package storj
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
 	"net/http"
@@ -32,7 +33,7 @@ func TestFilesList(t *testing.T) {
 	setup()
 	defer teardown()
 
-	_, err := client.Files.List("xyz")
+	_, err := client.Files.List(context.Background(), "xyz")
 	if err == nil || err.Error() != "authentication required" {
 		t.Errorf("Files.List should require authentication")
 	}
@@ -51,7 +52,7 @@ func TestFilesList(t *testing.T) {
 		fmt.Fprintf(w, "[%s]", fileJson)
 	})
 
-	files, err := client.Files.List("xyz")
+	files, err := client.Files.List(context.Background(), "xyz")
 	if err != nil {
 		t.Errorf("Files.List returned error: %v", err)
 	}
@@ -66,7 +67,7 @@ func TestFilesDelete(t *testing.T) {
 	setup()
 	defer teardown()
 
-	err := client.Files.Delete("abc", "xyz")
+	err := client.Files.Delete(context.Background(), "abc", "xyz")
 	if err == nil || err.Error() != "authentication required" {
 		t.Errorf("Files.Delete should require authentication")
 	}
@@ -85,12 +86,38 @@ func TestFilesDelete(t *testing.T) {
 		w.WriteHeader(204)
 	})
 
-	err = client.Files.Delete("abc", "xyz")
+	err = client.Files.Delete(context.Background(), "abc", "xyz")
 	if err != nil {
 		t.Errorf("Files.Delete returned error: %v", err)
 	}
 }
 
+func TestFilesInfo(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	mux.HandleFunc("/buckets/abc/files/xyz/info", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "GET")
+		if r.Header.Get("x-signature") == "" {
+			t.Errorf(`missing "x-signature" header`)
+		}
+		fmt.Fprint(w, `{"id": "xyz", "bucket": "abc", "filename": "a.txt", "mimetype": "text/plain", "frame": "f", "size": 3, "index": "0a0b"}`)
+	})
+
+	f, err := client.Files.Info(context.Background(), "abc", "xyz")
+	if err != nil {
+		t.Fatalf("Files.Info returned error: %v", err)
+	}
+
+	expected := &File{ID: "xyz", Bucket: "abc", Name: "a.txt", MimeType: "text/plain", Frame: "f", Size: 3, Index: "0a0b"}
+	if !reflect.DeepEqual(f, expected) {
+		t.Errorf("Files.Info returned %+v, expected %+v", f, expected)
+	}
+}
+
 func TestFilesListPointers(t *testing.T) {
 	setup()
 	defer teardown()
@@ -113,7 +140,7 @@ func TestFilesListPointers(t *testing.T) {
   }]`)
 	})
 
-	fps, err := client.Files.ListPointers("abc", "xyz", "a_token")
+	fps, err := client.Files.ListPointers(context.Background(), "abc", "xyz", "a_token")
 	if err != nil {
 		t.Errorf("Files.ListPointers returned error: %v", err)
 	}
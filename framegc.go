@@ -0,0 +1,146 @@
+package storj
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultFrameGracePeriod is how old an unreferenced frame must be before
+// CollectGarbage deletes it when GCOptions.GracePeriod is not set. It leaves
+// uploads that are still in progress alone.
+const DefaultFrameGracePeriod = 24 * time.Hour
+
+// GCOptions configures FrameService.CollectGarbage.
+type GCOptions struct {
+	// DryRun lists the frames that would be deleted without deleting
+	// anything.
+	DryRun bool
+
+	// GracePeriod is the minimum age of a frame that is deleted.
+	GracePeriod time.Duration
+
+	// SharedBuckets lists buckets other users own that this user uploads
+	// to, such as buckets shared through their PubKeys. Buckets.List does
+	// not return them, so their files are only checked if listed here.
+	SharedBuckets []string
+}
+
+// FrameError is a failure to process a single frame.
+type FrameError struct {
+	Frame Frame
+	Err   error
+}
+
+func (e *FrameError) Error() string {
+	return fmt.Sprintf("frame %s: %v", e.Frame.ID, e.Err)
+}
+
+func (e *FrameError) Unwrap() error {
+	return e.Err
+}
+
+// GCError is returned by CollectGarbage when some orphaned frames could not
+// be deleted.
+type GCError struct {
+	Failed []FrameError
+}
+
+func (e *GCError) Error() string {
+	msgs := make([]string, len(e.Failed))
+	for i := range e.Failed {
+		msgs[i] = e.Failed[i].Error()
+	}
+	return fmt.Sprintf("failed to delete %d frames: %s", len(e.Failed), strings.Join(msgs, "; "))
+}
+
+// CollectGarbage deletes frames that no file in any bucket refers to and that
+// are older than the grace period. Such frames are left behind by failed
+// uploads and still count against storage. Locked frames, which the Bridge
+// has already turned into files, are never deleted, even when the file is in
+// a bucket CollectGarbage cannot see. It returns the frames that were
+// deleted, or with DryRun set, the frames that would have been. If a frame
+// cannot be deleted, the rest are still attempted and a *GCError lists the
+// failures.
+func (s *FrameService) CollectGarbage(ctx context.Context, opts GCOptions) ([]Frame, error) {
+	grace := opts.GracePeriod
+	if grace <= 0 {
+		grace = DefaultFrameGracePeriod
+	}
+
+	// List frames before files, so a frame created and referenced in between
+	// is either too new to collect or seen as referenced.
+	frames, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced, err := s.referencedFrames(ctx, opts.SharedBuckets)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-grace)
+
+	var orphans []Frame
+	for _, f := range frames {
+		if f.Locked || referenced[f.ID] || f.Created.After(cutoff) {
+			continue
+		}
+		orphans = append(orphans, f)
+	}
+	if opts.DryRun {
+		return orphans, nil
+	}
+
+	var (
+		deleted []Frame
+		failed  []FrameError
+	)
+	for _, f := range orphans {
+		if err := s.Delete(ctx, f.ID); err != nil {
+			if IsNotFound(err) {
+				continue
+			}
+			failed = append(failed, FrameError{Frame: f, Err: err})
+			continue
+		}
+		deleted = append(deleted, f)
+	}
+
+	if len(failed) > 0 {
+		return deleted, &GCError{Failed: failed}
+	}
+
+	return deleted, nil
+}
+
+// referencedFrames returns the set of frame IDs used by files in any of the
+// user's buckets or in shared.
+func (s *FrameService) referencedFrames(ctx context.Context, shared []string) (map[string]bool, error) {
+	buckets, err := s.client.Buckets.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := append([]string{}, shared...)
+	for _, b := range buckets {
+		ids = append(ids, b.ID)
+	}
+
+	referenced := make(map[string]bool)
+	for _, id := range ids {
+		files, err := s.client.Files.List(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("bucket %s: %w", id, err)
+		}
+		for _, f := range files {
+			if f.Frame != "" {
+				referenced[f.Frame] = true
+			}
+		}
+	}
+
+	return referenced, nil
+}
@@ -0,0 +1,200 @@
+package storj
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// handleFrameGC serves two buckets whose files reference f1 and f2, and the
+// frames f1 to f5. f4 is too recent to collect. Deleting any frame listed in
+// fail returns an error.
+func handleFrameGC(t *testing.T, fail map[string]bool) (deleted map[string]bool) {
+	var mu sync.Mutex
+	deleted = make(map[string]bool)
+
+	old := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	mux.HandleFunc("/buckets", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "GET")
+		fmt.Fprint(w, `[{"id": "b1"}, {"id": "b2"}]`)
+	})
+	mux.HandleFunc("/buckets/b1/files", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": "a", "frame": "f1"}]`)
+	})
+	mux.HandleFunc("/buckets/b2/files", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": "b", "frame": "f2"}, {"id": "c"}]`)
+	})
+	mux.HandleFunc("/frames", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "GET")
+		fmt.Fprintf(w, `[
+  {"id": "f1", "created": %q},
+  {"id": "f2", "created": %q},
+  {"id": "f3", "created": %q},
+  {"id": "f4", "created": %q},
+  {"id": "f5", "created": %q}
+]`, old, old, old, recent, old)
+	})
+	mux.HandleFunc("/frames/", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "DELETE")
+		id := strings.TrimPrefix(r.URL.Path, "/frames/")
+		if fail[id] {
+			w.WriteHeader(500)
+			return
+		}
+
+		mu.Lock()
+		deleted[id] = true
+		mu.Unlock()
+		w.WriteHeader(204)
+	})
+
+	return deleted
+}
+
+func frameIDs(frames []Frame) []string {
+	ids := make([]string, len(frames))
+	for i, f := range frames {
+		ids[i] = f.ID
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestFramesCollectGarbage(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	deleted := handleFrameGC(t, nil)
+
+	frames, err := client.Frames.CollectGarbage(context.Background(), GCOptions{})
+	if err != nil {
+		t.Fatalf("Frames.CollectGarbage returned error: %v", err)
+	}
+
+	if ids := frameIDs(frames); strings.Join(ids, ",") != "f3,f5" {
+		t.Errorf("Frames.CollectGarbage returned %v, expected [f3 f5]", ids)
+	}
+	if len(deleted) != 2 || !deleted["f3"] || !deleted["f5"] {
+		t.Errorf("deleted frames %v, expected f3 and f5", deleted)
+	}
+}
+
+func TestFramesCollectGarbageGracePeriod(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	handleFrameGC(t, nil)
+
+	frames, err := client.Frames.CollectGarbage(context.Background(), GCOptions{DryRun: true, GracePeriod: time.Minute})
+	if err != nil {
+		t.Fatalf("Frames.CollectGarbage returned error: %v", err)
+	}
+
+	if ids := frameIDs(frames); strings.Join(ids, ",") != "f3,f4,f5" {
+		t.Errorf("Frames.CollectGarbage returned %v, expected [f3 f4 f5]", ids)
+	}
+}
+
+func TestFramesCollectGarbageDryRun(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	deleted := handleFrameGC(t, nil)
+
+	frames, err := client.Frames.CollectGarbage(context.Background(), GCOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Frames.CollectGarbage returned error: %v", err)
+	}
+
+	if ids := frameIDs(frames); strings.Join(ids, ",") != "f3,f5" {
+		t.Errorf("Frames.CollectGarbage returned %v, expected [f3 f5]", ids)
+	}
+	if len(deleted) != 0 {
+		t.Errorf("dry run deleted frames %v", deleted)
+	}
+}
+
+func TestFramesCollectGarbagePartialFailure(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	deleted := handleFrameGC(t, map[string]bool{"f3": true})
+
+	frames, err := client.Frames.CollectGarbage(context.Background(), GCOptions{})
+
+	var gcErr *GCError
+	if !errors.As(err, &gcErr) {
+		t.Fatalf("expected *GCError, got %v", err)
+	}
+	if len(gcErr.Failed) != 1 || gcErr.Failed[0].Frame.ID != "f3" {
+		t.Errorf("GCError.Failed = %+v, expected f3", gcErr.Failed)
+	}
+	if ids := frameIDs(frames); strings.Join(ids, ",") != "f5" || !deleted["f5"] {
+		t.Errorf("Frames.CollectGarbage returned %v, expected [f5]", ids)
+	}
+}
+
+func TestFramesCollectGarbageSharedBuckets(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	old := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+
+	// The user owns no buckets but has uploaded into shared bucket s1:
+	// f1 became a file and is locked, f2 is referenced but still unlocked,
+	// and f3 is a leftover.
+	mux.HandleFunc("/buckets", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/buckets/s1/files", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": "a", "frame": "f1"}, {"id": "b", "frame": "f2"}]`)
+	})
+	mux.HandleFunc("/frames", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[
+  {"id": "f1", "created": %q, "locked": true},
+  {"id": "f2", "created": %q},
+  {"id": "f3", "created": %q}
+]`, old, old, old)
+	})
+
+	ctx := context.Background()
+
+	frames, err := client.Frames.CollectGarbage(ctx, GCOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Frames.CollectGarbage returned error: %v", err)
+	}
+	if ids := frameIDs(frames); strings.Join(ids, ",") != "f2,f3" {
+		t.Errorf("without SharedBuckets, Frames.CollectGarbage returned %v, expected [f2 f3]", ids)
+	}
+
+	frames, err = client.Frames.CollectGarbage(ctx, GCOptions{DryRun: true, SharedBuckets: []string{"s1"}})
+	if err != nil {
+		t.Fatalf("Frames.CollectGarbage returned error: %v", err)
+	}
+	if ids := frameIDs(frames); strings.Join(ids, ",") != "f3" {
+		t.Errorf("with SharedBuckets, Frames.CollectGarbage returned %v, expected [f3]", ids)
+	}
+}
@@ -0,0 +1,106 @@
+package storj
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type FrameService struct {
+	client *Client
+}
+
+// Frame is a staging area the Bridge uses to collect a file's shards before
+// the file is added to a bucket.
+type Frame struct {
+	ID      string    `json:"id"`
+	User    string    `json:"user"`
+	Created time.Time `json:"created"`
+	Locked  bool      `json:"locked"`
+	Size    int64     `json:"size"`
+	Shards  []Shard   `json:"shards"`
+}
+
+// Shard describes one piece of a file: its position, the RIPEMD-160 of
+// SHA-256 hash of its data, and the audit tree leaves and challenges the
+// farmer will be asked to prove storage with.
+type Shard struct {
+	Index      int      `json:"index"`
+	Hash       string   `json:"hash"`
+	Size       int64    `json:"size"`
+	Tree       []string `json:"tree"`
+	Challenges []string `json:"challenges"`
+}
+
+func (s *FrameService) New(ctx context.Context) (*Frame, error) {
+	req, err := s.client.newSignedBodyRequest(ctx, "POST", "/frames", struct{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	var frame Frame
+	_, err = s.client.Do(req, &frame)
+	if err != nil {
+		return nil, err
+	}
+
+	return &frame, nil
+}
+
+func (s *FrameService) List(ctx context.Context) ([]Frame, error) {
+	req, err := s.client.newSignedRequest(ctx, "GET", "/frames")
+	if err != nil {
+		return nil, err
+	}
+
+	var frames []Frame
+	_, err = s.client.Do(req, &frames)
+	if err != nil {
+		return nil, err
+	}
+
+	return frames, nil
+}
+
+func (s *FrameService) Get(ctx context.Context, frameID string) (*Frame, error) {
+	req, err := s.client.newSignedRequest(ctx, "GET", fmt.Sprintf("/frames/%s", frameID))
+	if err != nil {
+		return nil, err
+	}
+
+	var frame Frame
+	_, err = s.client.Do(req, &frame)
+	if err != nil {
+		return nil, err
+	}
+
+	return &frame, nil
+}
+
+func (s *FrameService) Delete(ctx context.Context, frameID string) error {
+	req, err := s.client.newSignedRequest(ctx, "DELETE", fmt.Sprintf("/frames/%s", frameID))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Do(req, nil)
+	return err
+}
+
+// AddShard adds shard metadata to a frame. The Bridge responds with a PUSH
+// pointer naming the farmer the shard data should be sent to.
+func (s *FrameService) AddShard(ctx context.Context, frameID string, shard Shard) (*FilePointer, error) {
+	path := fmt.Sprintf("/frames/%s", frameID)
+	req, err := s.client.newSignedBodyRequest(ctx, "PUT", path, &shard)
+	if err != nil {
+		return nil, err
+	}
+
+	var pointer FilePointer
+	_, err = s.client.Do(req, &pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pointer, nil
+}
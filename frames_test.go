@@ -0,0 +1,175 @@
+package storj
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	frameJson = `
+  {
+    "id": "707f1f77bcf86cd799439011",
+    "user": "gordon@storj.io",
+    "created": "2016-03-04T17:01:02.629Z",
+    "locked": false,
+    "size": 1024,
+    "shards": [
+      {
+        "index": 0,
+        "hash": "ba084d3f143f2896809d3f1d7dffed472b39d8de",
+        "size": 1024,
+        "tree": ["5ea8b8a2d3d0a0ef21e7c9e4d8b8c7dcd4f8fba5"],
+        "challenges": ["5b2a0c9e5ca5a9a1f8f13f1e6cd5b2c7e3a4d2d1e2c6c7d8b8a7f6e5d4c3b2a1"]
+      }
+    ]
+  }`
+)
+
+var exFrame = Frame{
+	ID:      "707f1f77bcf86cd799439011",
+	User:    "gordon@storj.io",
+	Created: time.Date(2016, 3, 4, 17, 1, 2, 629000000, time.UTC),
+	Size:    1024,
+	Shards: []Shard{{
+		Index:      0,
+		Hash:       "ba084d3f143f2896809d3f1d7dffed472b39d8de",
+		Size:       1024,
+		Tree:       []string{"5ea8b8a2d3d0a0ef21e7c9e4d8b8c7dcd4f8fba5"},
+		Challenges: []string{"5b2a0c9e5ca5a9a1f8f13f1e6cd5b2c7e3a4d2d1e2c6c7d8b8a7f6e5d4c3b2a1"}}}}
+
+func TestFramesNew(t *testing.T) {
+	setup()
+	defer teardown()
+
+	_, err := client.Frames.New(context.Background())
+	if err == nil || err.Error() != "authentication required" {
+		t.Errorf("Frames.New should require authentication")
+	}
+
+	enableAuth()
+	defer disableAuth()
+
+	pubKey := hex.EncodeToString(privKey.PubKey().SerializeCompressed())
+
+	mux.HandleFunc("/frames", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "POST")
+		assertHeader(t, r, "x-pubkey", pubKey)
+		if r.Header.Get("x-signature") == "" {
+			t.Errorf(`missing "x-signature" header`)
+		}
+		fmt.Fprint(w, frameJson)
+	})
+
+	frame, err := client.Frames.New(context.Background())
+	if err != nil {
+		t.Errorf("Frames.New returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(frame, &exFrame) {
+		t.Errorf("Frames.New returned %+v, expected %+v", frame, exFrame)
+	}
+}
+
+func TestFramesList(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	mux.HandleFunc("/frames", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "GET")
+		fmt.Fprintf(w, "[%s]", frameJson)
+	})
+
+	frames, err := client.Frames.List(context.Background())
+	if err != nil {
+		t.Errorf("Frames.List returned error: %v", err)
+	}
+
+	expected := []Frame{exFrame}
+	if !reflect.DeepEqual(frames, expected) {
+		t.Errorf("Frames.List returned %+v, expected %+v", frames, expected)
+	}
+}
+
+func TestFramesGet(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	mux.HandleFunc("/frames/xyz", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "GET")
+		fmt.Fprint(w, frameJson)
+	})
+
+	frame, err := client.Frames.Get(context.Background(), "xyz")
+	if err != nil {
+		t.Errorf("Frames.Get returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(frame, &exFrame) {
+		t.Errorf("Frames.Get returned %+v, expected %+v", frame, exFrame)
+	}
+}
+
+func TestFramesDelete(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	mux.HandleFunc("/frames/xyz", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "DELETE")
+		w.WriteHeader(204)
+	})
+
+	err := client.Frames.Delete(context.Background(), "xyz")
+	if err != nil {
+		t.Errorf("Frames.Delete returned error: %v", err)
+	}
+}
+
+func TestFramesAddShard(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	mux.HandleFunc("/frames/xyz", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "PUT")
+		assertHeader(t, r, "Content-Type", "application/json")
+		var sent Shard
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Errorf("received bad JSON")
+		}
+		if !reflect.DeepEqual(sent, exFrame.Shards[0]) {
+			t.Errorf("sent shard %+v, expected %+v", sent, exFrame.Shards[0])
+		}
+		fmt.Fprintf(w, `{
+  "hash": "ba084d3f143f2896809d3f1d7dffed472b39d8de",
+  "token": "a_token",
+  "operation": "PUSH",
+  "farmer": {"address": "api.storj.io", "port": 8443, "nodeID": "32033d2dc11b877df4b1caefbffba06495ae6b18"}
+}`)
+	})
+
+	pointer, err := client.Frames.AddShard(context.Background(), "xyz", exFrame.Shards[0])
+	if err != nil {
+		t.Fatalf("Frames.AddShard returned error: %v", err)
+	}
+
+	if pointer.Operation != "PUSH" || pointer.Token != "a_token" || pointer.Farmer.Port != 8443 {
+		t.Errorf("Frames.AddShard returned %+v", pointer)
+	}
+}
@@ -0,0 +1,397 @@
+package storj
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcutil/base58"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KeyFormat is an encoding for unencrypted private keys.
+type KeyFormat int
+
+const (
+	// KeyFormatHex is the raw 32-byte key, hex encoded.
+	KeyFormatHex KeyFormat = iota
+	// KeyFormatWIF is Bitcoin's Wallet Import Format for a compressed
+	// public key.
+	KeyFormatWIF
+	// KeyFormatPEM is a SEC 1 "EC PRIVATE KEY" PEM block.
+	KeyFormatPEM
+)
+
+const (
+	wifVersion        = 0x80
+	wifTestnetVersion = 0xef
+
+	pemBlockType = "EC PRIVATE KEY"
+
+	keyFileVersion = 1
+	keyFileKDF     = "scrypt"
+	keyFileCipher  = "aes-256-gcm"
+)
+
+// Parameters for newly encrypted key files. Existing files record their own.
+var (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// Limits on the scrypt parameters read from a key file, so a damaged or
+// hostile file cannot make decryption use unbounded memory or time. scrypt
+// needs about 128*N*r bytes.
+const (
+	maxScryptMemory = 256 << 20
+	maxScryptP      = 16
+)
+
+var (
+	// ErrKeyEncrypted is returned when a passphrase is needed to read a key.
+	ErrKeyEncrypted = errors.New("key is encrypted")
+
+	// ErrBadPassphrase is returned when an encrypted key cannot be
+	// decrypted, either because the passphrase is wrong or the file has been
+	// modified.
+	ErrBadPassphrase = errors.New("incorrect passphrase or corrupt key")
+)
+
+var oidSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+
+// ecPrivateKey is the SEC 1 ASN.1 structure for an EC private key.
+type ecPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// encryptedKey is the on-disk format for passphrase-protected keys.
+type encryptedKey struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       string `json:"salt"`
+	Cipher     string `json:"cipher"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+func (c *Client) LoadAuthKey(fileName string) error {
+	privKey, err := ReadAuthKey(fileName)
+	if err != nil {
+		return err
+	}
+
+	c.AuthKey = privKey
+
+	return nil
+}
+
+// LoadEncryptedAuthKey sets AuthKey from a passphrase-protected key file.
+func (c *Client) LoadEncryptedAuthKey(fileName string, passphrase []byte) error {
+	privKey, err := ReadEncryptedAuthKey(fileName, passphrase)
+	if err != nil {
+		return err
+	}
+
+	c.AuthKey = privKey
+
+	return nil
+}
+
+// SaveAuthKey writes AuthKey to fileName in the given format.
+func (c *Client) SaveAuthKey(fileName string, format KeyFormat) error {
+	if c.AuthKey == nil {
+		return errors.New("no auth key")
+	}
+
+	return WriteAuthKey(fileName, c.AuthKey, format)
+}
+
+// SaveEncryptedAuthKey writes AuthKey to fileName encrypted with passphrase.
+func (c *Client) SaveEncryptedAuthKey(fileName string, passphrase []byte) error {
+	if c.AuthKey == nil {
+		return errors.New("no auth key")
+	}
+
+	return WriteEncryptedAuthKey(fileName, c.AuthKey, passphrase)
+}
+
+// ReadAuthKey reads an unencrypted private key from fileName. The format is
+// detected automatically.
+func ReadAuthKey(fileName string) (*btcec.PrivateKey, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeAuthKey(data)
+}
+
+// ReadEncryptedAuthKey reads a passphrase-protected private key from fileName.
+func ReadEncryptedAuthKey(fileName string, passphrase []byte) (*btcec.PrivateKey, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecryptAuthKey(data, passphrase)
+}
+
+// WriteAuthKey writes key to fileName in the given format, readable only by
+// its owner.
+func WriteAuthKey(fileName string, key *btcec.PrivateKey, format KeyFormat) error {
+	data, err := EncodeAuthKey(key, format)
+	if err != nil {
+		return err
+	}
+
+	return writeKeyFile(fileName, data)
+}
+
+// WriteEncryptedAuthKey writes key to fileName encrypted with passphrase,
+// readable only by its owner.
+func WriteEncryptedAuthKey(fileName string, key *btcec.PrivateKey, passphrase []byte) error {
+	data, err := EncryptAuthKey(key, passphrase)
+	if err != nil {
+		return err
+	}
+
+	return writeKeyFile(fileName, data)
+}
+
+func writeKeyFile(fileName string, data []byte) error {
+	f, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	// OpenFile leaves the mode of an existing file alone.
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// DecodeAuthKey parses an unencrypted private key in any KeyFormat.
+func DecodeAuthKey(data []byte) (*btcec.PrivateKey, error) {
+	data = bytes.TrimSpace(data)
+
+	switch {
+	case bytes.HasPrefix(data, []byte("-----BEGIN")):
+		return decodePEMKey(data)
+	case bytes.HasPrefix(data, []byte("{")):
+		return nil, ErrKeyEncrypted
+	case len(data) == 2*btcec.PrivKeyBytesLen:
+		keyBytes, err := hex.DecodeString(string(data))
+		if err != nil {
+			return nil, err
+		}
+		return privKeyFromBytes(keyBytes)
+	}
+
+	return decodeWIFKey(string(data))
+}
+
+// EncodeAuthKey serializes key in the given format.
+func EncodeAuthKey(key *btcec.PrivateKey, format KeyFormat) ([]byte, error) {
+	keyBytes := key.Serialize()
+
+	switch format {
+	case KeyFormatHex:
+		return []byte(hex.EncodeToString(keyBytes) + "\n"), nil
+
+	case KeyFormatWIF:
+		payload := append(keyBytes, 0x01)
+		return []byte(base58.CheckEncode(payload, wifVersion) + "\n"), nil
+
+	case KeyFormatPEM:
+		der, err := asn1.Marshal(ecPrivateKey{
+			Version:       1,
+			PrivateKey:    keyBytes,
+			NamedCurveOID: oidSecp256k1,
+			PublicKey:     asn1.BitString{Bytes: key.PubKey().SerializeUncompressed()},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: pemBlockType, Bytes: der}), nil
+	}
+
+	return nil, fmt.Errorf("unknown key format %d", format)
+}
+
+// EncryptAuthKey encrypts key with a key derived from passphrase using
+// scrypt, sealing it with AES-256-GCM.
+func EncryptAuthKey(key *btcec.PrivateKey, passphrase []byte) ([]byte, error) {
+	salt := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	ek := encryptedKey{
+		Version: keyFileVersion,
+		KDF:     keyFileKDF,
+		N:       scryptN,
+		R:       scryptR,
+		P:       scryptP,
+		Salt:    hex.EncodeToString(salt),
+		Cipher:  keyFileCipher,
+	}
+
+	aead, err := ek.aead(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	ek.Nonce = hex.EncodeToString(nonce)
+	ek.Ciphertext = hex.EncodeToString(aead.Seal(nil, nonce, key.Serialize(), nil))
+
+	j, err := json.MarshalIndent(&ek, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(j, '\n'), nil
+}
+
+// DecryptAuthKey decrypts a key produced by EncryptAuthKey.
+func DecryptAuthKey(data, passphrase []byte) (*btcec.PrivateKey, error) {
+	var ek encryptedKey
+	if err := json.Unmarshal(data, &ek); err != nil {
+		return nil, fmt.Errorf("malformed encrypted key: %v", err)
+	}
+	if ek.Version != keyFileVersion || ek.KDF != keyFileKDF || ek.Cipher != keyFileCipher {
+		return nil, fmt.Errorf("unsupported encrypted key (version %d, kdf %q, cipher %q)", ek.Version, ek.KDF, ek.Cipher)
+	}
+
+	salt, err := hex.DecodeString(ek.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("malformed encrypted key: %v", err)
+	}
+	nonce, err := hex.DecodeString(ek.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("malformed encrypted key: %v", err)
+	}
+	ciphertext, err := hex.DecodeString(ek.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("malformed encrypted key: %v", err)
+	}
+
+	aead, err := ek.aead(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, errors.New("malformed encrypted key: bad nonce")
+	}
+
+	keyBytes, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrBadPassphrase
+	}
+
+	return privKeyFromBytes(keyBytes)
+}
+
+func (ek *encryptedKey) aead(passphrase, salt []byte) (cipher.AEAD, error) {
+	if ek.N <= 1 || ek.R <= 0 || ek.P <= 0 || ek.P > maxScryptP ||
+		ek.R > maxScryptMemory/128 || ek.N > maxScryptMemory/128/ek.R {
+		return nil, fmt.Errorf("malformed encrypted key: scrypt parameters N=%d r=%d p=%d out of range", ek.N, ek.R, ek.P)
+	}
+
+	dk, err := scrypt.Key(passphrase, salt, ek.N, ek.R, ek.P, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(dk)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func decodePEMKey(data []byte) (*btcec.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemBlockType {
+		return nil, fmt.Errorf("expected a PEM %q block", pemBlockType)
+	}
+
+	var k ecPrivateKey
+	if _, err := asn1.Unmarshal(block.Bytes, &k); err != nil {
+		return nil, fmt.Errorf("malformed SEC 1 key: %v", err)
+	}
+	if k.Version != 1 {
+		return nil, fmt.Errorf("unsupported SEC 1 key version %d", k.Version)
+	}
+	if len(k.NamedCurveOID) != 0 && !k.NamedCurveOID.Equal(oidSecp256k1) {
+		return nil, fmt.Errorf("key is not on secp256k1 (curve %v)", k.NamedCurveOID)
+	}
+
+	return privKeyFromBytes(k.PrivateKey)
+}
+
+func decodeWIFKey(s string) (*btcec.PrivateKey, error) {
+	payload, version, err := base58.CheckDecode(s)
+	if err != nil {
+		return nil, errors.New("unrecognized key format")
+	}
+	if version != wifVersion && version != wifTestnetVersion {
+		return nil, fmt.Errorf("unexpected WIF version byte %#x", version)
+	}
+
+	switch {
+	case len(payload) == btcec.PrivKeyBytesLen:
+	case len(payload) == btcec.PrivKeyBytesLen+1 && payload[btcec.PrivKeyBytesLen] == 0x01:
+		payload = payload[:btcec.PrivKeyBytesLen]
+	default:
+		return nil, errors.New("malformed WIF key")
+	}
+
+	return privKeyFromBytes(payload)
+}
+
+// privKeyFromBytes is btcec.PrivKeyFromBytes with the range check it lacks.
+func privKeyFromBytes(b []byte) (*btcec.PrivateKey, error) {
+	if len(b) != btcec.PrivKeyBytesLen {
+		return nil, fmt.Errorf("private key must be %d bytes, got %d", btcec.PrivKeyBytesLen, len(b))
+	}
+
+	d := new(big.Int).SetBytes(b)
+	if d.Sign() == 0 || d.Cmp(btcec.S256().N) >= 0 {
+		return nil, errors.New("private key is out of range")
+	}
+
+	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), b)
+	return privKey, nil
+}
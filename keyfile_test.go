@@ -0,0 +1,180 @@
+package storj
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// From https://en.bitcoin.it/wiki/Wallet_import_format
+const (
+	testKeyHex = "0c28fca386c7a227600b2fe50b7cae11ec86d3bf1fbe471be89827e19d72aa1d"
+	testKeyWIF = "KwdMAjGmerYanjeui5SHS7JkmpZvVipYvB2LJGU1ZxJwYvP98617"
+
+	testKeyWIFUncompressed = "5HueCGU8rMjxEXxiPuD5BDku4MkFqeZyd4dZ1jvhTVqvbTLvyTJ"
+)
+
+func init() {
+	// Keep encryption tests fast.
+	scryptN = 1 << 10
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "storj-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestDecodeAuthKey(t *testing.T) {
+	inputs := []string{
+		testKeyHex,
+		testKeyHex + "\n",
+		testKeyWIF,
+		testKeyWIFUncompressed + "\r\n",
+	}
+
+	for _, in := range inputs {
+		key, err := DecodeAuthKey([]byte(in))
+		if err != nil {
+			t.Errorf("DecodeAuthKey(%q) returned error: %v", in, err)
+			continue
+		}
+		if h := hex.EncodeToString(key.Serialize()); h != testKeyHex {
+			t.Errorf("DecodeAuthKey(%q) returned key %s", in, h)
+		}
+	}
+}
+
+func TestDecodeAuthKeyInvalid(t *testing.T) {
+	inputs := []string{
+		"",
+		"not a key",
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff0",
+		testKeyWIF[:len(testKeyWIF)-1] + "8",
+	}
+
+	for _, in := range inputs {
+		if _, err := DecodeAuthKey([]byte(in)); err == nil {
+			t.Errorf("DecodeAuthKey(%q) should have returned an error", in)
+		}
+	}
+}
+
+func TestEncodeAuthKey(t *testing.T) {
+	key, _ := DecodeAuthKey([]byte(testKeyHex))
+
+	for _, format := range []KeyFormat{KeyFormatHex, KeyFormatWIF, KeyFormatPEM} {
+		data, err := EncodeAuthKey(key, format)
+		if err != nil {
+			t.Errorf("EncodeAuthKey(%d) returned error: %v", format, err)
+			continue
+		}
+
+		decoded, err := DecodeAuthKey(data)
+		if err != nil {
+			t.Errorf("DecodeAuthKey(%d) returned error: %v", format, err)
+			continue
+		}
+		if !bytes.Equal(decoded.Serialize(), key.Serialize()) {
+			t.Errorf("format %d did not round-trip", format)
+		}
+	}
+
+	wif, _ := EncodeAuthKey(key, KeyFormatWIF)
+	if string(bytes.TrimSpace(wif)) != testKeyWIF {
+		t.Errorf("EncodeAuthKey returned WIF %q, expected %q", wif, testKeyWIF)
+	}
+}
+
+func TestEncryptAuthKey(t *testing.T) {
+	data, err := EncryptAuthKey(privKey, []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("EncryptAuthKey returned error: %v", err)
+	}
+
+	if _, err := DecodeAuthKey(data); err != ErrKeyEncrypted {
+		t.Errorf("DecodeAuthKey should return ErrKeyEncrypted, got %v", err)
+	}
+	if _, err := DecryptAuthKey(data, []byte("battery staple")); err != ErrBadPassphrase {
+		t.Errorf("DecryptAuthKey should return ErrBadPassphrase, got %v", err)
+	}
+
+	key, err := DecryptAuthKey(data, []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("DecryptAuthKey returned error: %v", err)
+	}
+	if !bytes.Equal(key.Serialize(), privKey.Serialize()) {
+		t.Errorf("encrypted key did not round-trip")
+	}
+}
+
+func TestDecryptAuthKeyScryptLimits(t *testing.T) {
+	data, err := EncryptAuthKey(privKey, []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("EncryptAuthKey returned error: %v", err)
+	}
+
+	for _, field := range []string{`"n":1073741824`, `"r":1048576`, `"p":1000`, `"n":0`} {
+		var fields map[string]json.RawMessage
+		json.Unmarshal(data, &fields)
+		kv := strings.SplitN(field, ":", 2)
+		fields[strings.Trim(kv[0], `"`)] = json.RawMessage(kv[1])
+		hostile, _ := json.Marshal(fields)
+
+		_, err := DecryptAuthKey(hostile, []byte("correct horse"))
+		if err == nil || err == ErrBadPassphrase || !strings.Contains(err.Error(), "out of range") {
+			t.Errorf("%s: expected scrypt parameters to be rejected, got %v", field, err)
+		}
+	}
+}
+
+func TestSaveAndLoadAuthKey(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	c, _ := NewClient(WithAuthKey(privKey))
+	plain := filepath.Join(dir, "plain.key")
+	encrypted := filepath.Join(dir, "encrypted.key")
+
+	// An existing file keeps its mode unless the writer resets it.
+	ioutil.WriteFile(plain, nil, 0644)
+
+	if err := c.SaveAuthKey(plain, KeyFormatPEM); err != nil {
+		t.Fatalf("SaveAuthKey returned error: %v", err)
+	}
+	if err := c.SaveEncryptedAuthKey(encrypted, []byte("pass")); err != nil {
+		t.Fatalf("SaveEncryptedAuthKey returned error: %v", err)
+	}
+
+	for _, fileName := range []string{plain, encrypted} {
+		fi, err := os.Stat(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode().Perm() != 0600 {
+			t.Errorf("%s has mode %v, expected 0600", filepath.Base(fileName), fi.Mode().Perm())
+		}
+	}
+
+	loaded, _ := NewClient()
+	if err := loaded.LoadAuthKey(plain); err != nil {
+		t.Errorf("LoadAuthKey returned error: %v", err)
+	} else if !bytes.Equal(loaded.AuthKey.Serialize(), privKey.Serialize()) {
+		t.Errorf("LoadAuthKey loaded the wrong key")
+	}
+
+	loaded.AuthKey = nil
+	if err := loaded.LoadEncryptedAuthKey(encrypted, []byte("pass")); err != nil {
+		t.Errorf("LoadEncryptedAuthKey returned error: %v", err)
+	} else if !bytes.Equal(loaded.AuthKey.Serialize(), privKey.Serialize()) {
+		t.Errorf("LoadEncryptedAuthKey loaded the wrong key")
+	}
+}
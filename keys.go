@@ -1,11 +1,8 @@
 package storj
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
-	"net/url"
 )
 
 type KeyService struct {
@@ -17,8 +14,8 @@ type Key struct {
 	User string `json:"user"`
 }
 
-func (s *KeyService) List() ([]Key, error) {
-	req, err := s.client.newSignedRequest("GET", "/keys")
+func (s *KeyService) List(ctx context.Context) ([]Key, error) {
+	req, err := s.client.newSignedRequest(ctx, "GET", "/keys")
 	if err != nil {
 		return nil, err
 	}
@@ -32,34 +29,14 @@ func (s *KeyService) List() ([]Key, error) {
 	return keys, nil
 }
 
-func (s *KeyService) Register(key string) error {
-	nonce, err := s.client.generateNonce()
-	if err != nil {
-		return err
-	}
-
+func (s *KeyService) Register(ctx context.Context, key string) error {
 	k := struct {
-		Key   string `json:"key"`
-		Nonce string `json:"__nonce"`
+		Key string `json:"key"`
 	}{
 		key,
-		nonce,
-	}
-
-	j, err := json.Marshal(&k)
-	if err != nil {
-		return err
-	}
-
-	rel, _ := url.Parse("/keys")
-	url := s.client.BaseURL.ResolveReference(rel)
-	req, err := http.NewRequest("POST", url.String(), bytes.NewBuffer(j))
-	if err != nil {
-		return err
 	}
 
-	msg := fmt.Sprintf("POST\n/keys\n%s", j)
-	err = s.client.signRequest(req, msg)
+	req, err := s.client.newSignedBodyRequest(ctx, "POST", "/keys", &k)
 	if err != nil {
 		return err
 	}
@@ -76,19 +53,12 @@ func (s *KeyService) Register(key string) error {
 	return nil
 }
 
-func (s *KeyService) Delete(key string) error {
-	req, err := s.client.newSignedRequest("DELETE", fmt.Sprintf("/keys/%s", key))
+func (s *KeyService) Delete(ctx context.Context, key string) error {
+	req, err := s.client.newSignedRequest(ctx, "DELETE", fmt.Sprintf("/keys/%s", key))
 	if err != nil {
 		return err
 	}
 
-	resp, err := s.client.client.Do(req)
-	if err != nil {
-		return err
-	}
-	if resp.StatusCode != 204 {
-		return fmt.Errorf("expected status 204, got %d", resp.StatusCode)
-	}
-
-	return nil
+	_, err = s.client.Do(req, nil)
+	return err
 }
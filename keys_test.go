@@ -1,6 +1,7 @@
 package storj
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -13,7 +14,7 @@ func TestKeysList(t *testing.T) {
 	setup()
 	defer teardown()
 
-	_, err := client.Keys.List()
+	_, err := client.Keys.List(context.Background())
 	if err == nil || err.Error() != "authentication required" {
 		t.Errorf("Keys.List should require authentication")
 	}
@@ -32,7 +33,7 @@ func TestKeysList(t *testing.T) {
 		fmt.Fprintf(w, `[{"key": "031a259ee122414f57a63bbd6887ee17960e9106b0adcf89a298cdad2108adf4d9", "user": "gordon@storj.io"}]`)
 	})
 
-	keys, err := client.Keys.List()
+	keys, err := client.Keys.List(context.Background())
 	if err != nil {
 		t.Errorf("Keys.List returned error: %v", err)
 	}
@@ -49,7 +50,7 @@ func TestKeysRegister(t *testing.T) {
 	setup()
 	defer teardown()
 
-	err := client.Keys.Register("xyz")
+	err := client.Keys.Register(context.Background(), "xyz")
 	if err == nil || err.Error() != "authentication required" {
 		t.Errorf("Keys.Register should require authentication")
 	}
@@ -75,7 +76,7 @@ func TestKeysRegister(t *testing.T) {
 		fmt.Fprintf(w, `{"key": "xyz", "user": "gordon@storj.io"}`)
 	})
 
-	err = client.Keys.Register("xyz")
+	err = client.Keys.Register(context.Background(), "xyz")
 	if err != nil {
 		t.Errorf("Keys.Register returned error: %v", err)
 	}
@@ -85,7 +86,7 @@ func TestKeysDelete(t *testing.T) {
 	setup()
 	defer teardown()
 
-	err := client.Keys.Delete("xyz")
+	err := client.Keys.Delete(context.Background(), "xyz")
 	if err == nil || err.Error() != "authentication required" {
 		t.Errorf("Keys.Delete should require authentication")
 	}
@@ -104,7 +105,7 @@ func TestKeysDelete(t *testing.T) {
 		w.WriteHeader(204)
 	})
 
-	err = client.Keys.Delete("xyz")
+	err = client.Keys.Delete(context.Background(), "xyz")
 	if err != nil {
 		t.Errorf("Keys.Delete returned error: %v", err)
 	}
@@ -0,0 +1,107 @@
+package storj
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ManifestVersion is the manifest format written by Export.
+const ManifestVersion = 1
+
+// Manifest is a point-in-time record of a bucket's settings and contents.
+// It holds metadata only; file data stays with the farmers.
+type Manifest struct {
+	Version  int       `json:"version"`
+	Exported time.Time `json:"exported"`
+	Source   string    `json:"source"`
+	Bucket   Bucket    `json:"bucket"`
+	Files    []File    `json:"files"`
+}
+
+// Export records a bucket and every file in it, including frame IDs and MIME
+// types, in a Manifest.
+func (s *BucketService) Export(ctx context.Context, bucketID string) (*Manifest, error) {
+	bucket, err := s.Get(ctx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := s.client.Files.List(ctx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+	if files == nil {
+		files = []File{}
+	}
+
+	return &Manifest{
+		Version:  ManifestVersion,
+		Exported: time.Now().UTC(),
+		Source:   s.client.BaseURL.String(),
+		Bucket:   *bucket,
+		Files:    files,
+	}, nil
+}
+
+// Import creates a bucket with the name, limits and public keys recorded in
+// m, on whichever Bridge and account the client is using. Files are not
+// recreated, since the manifest does not contain their data.
+func (s *BucketService) Import(ctx context.Context, m *Manifest) (*Bucket, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	b := m.Bucket
+	for _, key := range b.PubKeys {
+		if err := ValidatePubKey(key); err != nil {
+			return nil, err
+		}
+	}
+
+	bucket, err := s.New(ctx, b.Name, b.Storage, b.Transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b.PubKeys) > 0 {
+		bucket, err = s.Update(ctx, bucket.ID, BucketUpdate{PubKeys: b.PubKeys})
+		if err != nil {
+			return nil, fmt.Errorf("bucket created but setting public keys failed: %w", err)
+		}
+	}
+
+	return bucket, nil
+}
+
+// ReadManifest decodes a manifest written by Manifest.Write.
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("malformed manifest: %v", err)
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// Write encodes the manifest as indented JSON.
+func (m *Manifest) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+func (m *Manifest) validate() error {
+	if m.Version != ManifestVersion {
+		return fmt.Errorf("unsupported manifest version %d", m.Version)
+	}
+	if m.Bucket.Name == "" {
+		return fmt.Errorf("manifest has no bucket name")
+	}
+	return nil
+}
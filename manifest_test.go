@@ -0,0 +1,100 @@
+package storj
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBucketsExport(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	mux.HandleFunc("/buckets/xyz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, bucketJson)
+	})
+	mux.HandleFunc("/buckets/xyz/files", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "[%s]", fileJson)
+	})
+
+	m, err := client.Buckets.Export(context.Background(), "xyz")
+	if err != nil {
+		t.Fatalf("Buckets.Export returned error: %v", err)
+	}
+
+	if m.Version != ManifestVersion || m.Source != server.URL {
+		t.Errorf("unexpected manifest header: version %d, source %q", m.Version, m.Source)
+	}
+	if !reflect.DeepEqual(m.Bucket, exBucket) {
+		t.Errorf("manifest has bucket %+v, expected %+v", m.Bucket, exBucket)
+	}
+	if !reflect.DeepEqual(m.Files, []File{exFile}) {
+		t.Errorf("manifest has files %+v, expected %+v", m.Files, []File{exFile})
+	}
+
+	var buf bytes.Buffer
+	if err := m.Write(&buf); err != nil {
+		t.Fatalf("Manifest.Write returned error: %v", err)
+	}
+	read, err := ReadManifest(&buf)
+	if err != nil {
+		t.Fatalf("ReadManifest returned error: %v", err)
+	}
+	if !reflect.DeepEqual(read.Files, m.Files) || !read.Exported.Equal(m.Exported) {
+		t.Errorf("manifest did not round-trip")
+	}
+}
+
+func TestBucketsImport(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	mux.HandleFunc("/buckets", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "POST")
+		var sent map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&sent)
+		if sent["name"] != exBucket.Name || sent["storage"] != float64(10) || sent["transfer"] != float64(30) {
+			t.Errorf("unexpected bucket parameters %v", sent)
+		}
+		fmt.Fprint(w, `{"id": "new", "name": "New Bucket", "pubkeys": []}`)
+	})
+
+	var patched []string
+	mux.HandleFunc("/buckets/new", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "PATCH")
+		var sent struct {
+			PubKeys []string `json:"pubkeys"`
+		}
+		json.NewDecoder(r.Body).Decode(&sent)
+		patched = sent.PubKeys
+		fmt.Fprintf(w, `{"id": "new", "name": "New Bucket", "pubkeys": [%q]}`, sent.PubKeys[0])
+	})
+
+	m := &Manifest{Version: ManifestVersion, Bucket: exBucket}
+	bucket, err := client.Buckets.Import(context.Background(), m)
+	if err != nil {
+		t.Fatalf("Buckets.Import returned error: %v", err)
+	}
+
+	if bucket.ID != "new" || !reflect.DeepEqual(patched, exBucket.PubKeys) {
+		t.Errorf("Buckets.Import returned %+v, set pubkeys %v", bucket, patched)
+	}
+}
+
+func TestReadManifestVersion(t *testing.T) {
+	_, err := ReadManifest(strings.NewReader(`{"version": 99, "bucket": {"name": "x"}}`))
+	if err == nil {
+		t.Errorf("ReadManifest should reject unknown versions")
+	}
+}
@@ -0,0 +1,176 @@
+package storj
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// Option configures a Client in NewClient.
+type Option func(*Client) error
+
+// WithBaseURL points the client at a Bridge other than api.storj.io.
+func WithBaseURL(rawURL string) Option {
+	return func(c *Client) error {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return fmt.Errorf("invalid base URL: %v", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("invalid base URL %q: scheme must be http or https", rawURL)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("invalid base URL %q: missing host", rawURL)
+		}
+
+		c.BaseURL = u
+		return nil
+	}
+}
+
+// WithHTTPClient sets the http.Client used to send requests. The client is
+// never modified; WithTimeout applies to a copy of it.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) error {
+		if hc == nil {
+			return errors.New("nil http client")
+		}
+
+		timeout := c.client.Timeout
+		c.client = hc
+		if timeout != 0 {
+			return WithTimeout(timeout)(c)
+		}
+		return nil
+	}
+}
+
+// WithAuthKey sets the key used to sign requests.
+func WithAuthKey(key *btcec.PrivateKey) Option {
+	return func(c *Client) error {
+		if key == nil {
+			return errors.New("nil auth key")
+		}
+
+		c.AuthKey = key
+		return nil
+	}
+}
+
+// WithSigner signs requests with s instead of an in-memory AuthKey.
+func WithSigner(s Signer) Option {
+	return func(c *Client) error {
+		if s == nil {
+			return errors.New("nil signer")
+		}
+
+		c.Signer = s
+		return nil
+	}
+}
+
+// WithBasicAuth authenticates with an email and password until an AuthKey is
+// set. See Client.SetBasicAuth.
+func WithBasicAuth(email, password string) Option {
+	return func(c *Client) error {
+		if email == "" {
+			return errors.New("empty email")
+		}
+
+		c.SetBasicAuth(email, password)
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) error {
+		c.userAgent = ua
+		return nil
+	}
+}
+
+// WithTimeout bounds the time taken by each HTTP request, including reading
+// the response body.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) error {
+		if d < 0 {
+			return fmt.Errorf("invalid timeout %v", d)
+		}
+
+		hc := *c.client
+		hc.Timeout = d
+		c.client = &hc
+		return nil
+	}
+}
+
+// WithNonceSource sets the source of randomness used to generate request
+// nonces. It defaults to crypto/rand.Reader.
+func WithNonceSource(r io.Reader) Option {
+	return func(c *Client) error {
+		if r == nil {
+			return errors.New("nil nonce source")
+		}
+
+		c.nonceSource = r
+		return nil
+	}
+}
+
+// WithBucketCacheTTL sets how long Buckets.GetByName caches bucket names. A
+// zero TTL disables the cache.
+func WithBucketCacheTTL(ttl time.Duration) Option {
+	return func(c *Client) error {
+		if ttl < 0 {
+			return fmt.Errorf("invalid bucket cache TTL %v", ttl)
+		}
+
+		c.bucketNames = newBucketNameCache(ttl)
+		return nil
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry idempotent requests.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) error {
+		c.RetryPolicy = &p
+		return nil
+	}
+}
+
+// WithEncryption encrypts files on Upload and decrypts them on Download with
+// keys derived from a BIP39 mnemonic. Keys are derived following libstorj's
+// scheme, but files are not known to be readable by other clients, nor theirs
+// by this one.
+func WithEncryption(mnemonic string) Option {
+	return func(c *Client) error {
+		mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+		if !validMnemonic(mnemonic) {
+			return errors.New("invalid mnemonic: must be 12, 15, 18, 21 or 24 words")
+		}
+
+		c.seed = MnemonicToSeed(mnemonic)
+		return nil
+	}
+}
+
+// WithEncryptionMode selects how Upload encrypts files, and which files
+// Download accepts, when WithEncryption is also given. The default is
+// EncryptionCTR.
+func WithEncryptionMode(mode EncryptionMode) Option {
+	return func(c *Client) error {
+		if mode != EncryptionCTR && mode != EncryptionAEAD {
+			return fmt.Errorf("invalid encryption mode %d", mode)
+		}
+
+		c.encryptionMode = mode
+		return nil
+	}
+}
@@ -0,0 +1,84 @@
+package storj
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewClientDefaults(t *testing.T) {
+	c, err := NewClient()
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+
+	if c.BaseURL.String() != defaultBaseURL {
+		t.Errorf("got base URL %q, expected %q", c.BaseURL, defaultBaseURL)
+	}
+	if c.client != http.DefaultClient {
+		t.Errorf("NewClient should use http.DefaultClient by default")
+	}
+}
+
+func TestNewClientInvalidOptions(t *testing.T) {
+	opts := map[string]Option{
+		"bad url":     WithBaseURL("://bad"),
+		"bad scheme":  WithBaseURL("ftp://api.storj.io"),
+		"no host":     WithBaseURL("https://"),
+		"nil client":  WithHTTPClient(nil),
+		"nil key":     WithAuthKey(nil),
+		"neg timeout": WithTimeout(-time.Second),
+		"nil nonce":   WithNonceSource(nil),
+	}
+
+	for name, opt := range opts {
+		if _, err := NewClient(opt); err == nil {
+			t.Errorf("%s: NewClient should have returned an error", name)
+		}
+	}
+}
+
+func TestNewClientTimeout(t *testing.T) {
+	hc := &http.Client{}
+	c, err := NewClient(WithTimeout(time.Second), WithHTTPClient(hc))
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+
+	if c.client.Timeout != time.Second {
+		t.Errorf("got timeout %v, expected %v", c.client.Timeout, time.Second)
+	}
+	if hc.Timeout != 0 {
+		t.Errorf("WithTimeout should not modify the caller's http.Client")
+	}
+}
+
+func TestNewClientUserAgentAndNonce(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var err error
+	client, err = NewClient(
+		WithBaseURL(server.URL),
+		WithAuthKey(privKey),
+		WithUserAgent("storj-test/1.0"),
+		WithNonceSource(bytes.NewReader(bytes.Repeat([]byte{0xab}, 16))),
+	)
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		assertHeader(t, r, "User-Agent", "storj-test/1.0")
+		if nonce := r.URL.Query().Get("__nonce"); nonce != "abababababababababababababababab" {
+			t.Errorf("got nonce %q from custom nonce source", nonce)
+		}
+		w.Write([]byte("[]"))
+	})
+
+	if _, err := client.Keys.List(context.Background()); err != nil {
+		t.Errorf("Keys.List returned error: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package storj
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// ValidatePubKey checks that key is a hex-encoded compressed secp256k1 public
+// key, the form the Bridge stores in Bucket.PubKeys.
+func ValidatePubKey(key string) error {
+	b, err := hex.DecodeString(key)
+	if err != nil {
+		return fmt.Errorf("invalid public key %q: %v", key, err)
+	}
+	if len(b) != btcec.PubKeyBytesLenCompressed || (b[0] != 0x02 && b[0] != 0x03) {
+		return fmt.Errorf("invalid public key %q: not a compressed key", key)
+	}
+	if new(big.Int).SetBytes(b[1:]).Cmp(btcec.S256().P) >= 0 {
+		return fmt.Errorf("invalid public key %q: coordinate out of range", key)
+	}
+	if _, err := btcec.ParsePubKey(b, btcec.S256()); err != nil {
+		return fmt.Errorf("invalid public key %q: %v", key, err)
+	}
+	return nil
+}
+
+// normalizePubKey validates key and returns it in lowercase, the form Client
+// and the Bridge produce, so keys can be compared as strings.
+func normalizePubKey(key string) (string, error) {
+	if err := ValidatePubKey(key); err != nil {
+		return "", err
+	}
+	return strings.ToLower(key), nil
+}
+
+// AddPubKey grants key access to a bucket. The bucket's key list is read and
+// written back, so concurrent changes to the same bucket may be lost.
+func (s *BucketService) AddPubKey(ctx context.Context, bucketID, key string) (*Bucket, error) {
+	key, err := normalizePubKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, err := s.Get(ctx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range bucket.PubKeys {
+		if strings.EqualFold(k, key) {
+			return bucket, nil
+		}
+	}
+
+	pubKeys := append(append([]string{}, bucket.PubKeys...), key)
+	return s.Update(ctx, bucketID, BucketUpdate{PubKeys: pubKeys})
+}
+
+// RemovePubKey revokes key's access to a bucket. Like AddPubKey, it is a
+// read-modify-write of the bucket's key list.
+func (s *BucketService) RemovePubKey(ctx context.Context, bucketID, key string) (*Bucket, error) {
+	key, err := normalizePubKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, err := s.Get(ctx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKeys, removed := withoutKey(bucket.PubKeys, key)
+	if !removed {
+		return bucket, nil
+	}
+
+	return s.Update(ctx, bucketID, BucketUpdate{PubKeys: pubKeys})
+}
+
+// RevokePubKey removes key from every bucket the user owns, returning the IDs
+// of the buckets it was removed from. It keeps going after a failure so that
+// as many buckets as possible are fixed, and reports every failure in the
+// returned error.
+func (s *BucketService) RevokePubKey(ctx context.Context, key string) ([]string, error) {
+	key, err := normalizePubKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var revoked []string
+	var failures []string
+	for _, b := range buckets {
+		pubKeys, removed := withoutKey(b.PubKeys, key)
+		if !removed {
+			continue
+		}
+
+		if _, err := s.Update(ctx, b.ID, BucketUpdate{PubKeys: pubKeys}); err != nil {
+			failures = append(failures, fmt.Sprintf("bucket %s: %v", b.ID, err))
+			continue
+		}
+		revoked = append(revoked, b.ID)
+	}
+
+	if len(failures) > 0 {
+		return revoked, fmt.Errorf("failed to revoke key from %d buckets: %s", len(failures), strings.Join(failures, "; "))
+	}
+
+	return revoked, nil
+}
+
+func withoutKey(keys []string, key string) ([]string, bool) {
+	out := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if !strings.EqualFold(k, key) {
+			out = append(out, k)
+		}
+	}
+	return out, len(out) != len(keys)
+}
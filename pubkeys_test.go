@@ -0,0 +1,177 @@
+package storj
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const otherPubKey = "03ecde6e57b31d529ac9473773ba51aeb2185cc5cb24e45864cfe758405c82547b"
+
+func TestValidatePubKey(t *testing.T) {
+	valid := PubKeyHex(privKey.PubKey())
+	if err := ValidatePubKey(valid); err != nil {
+		t.Errorf("ValidatePubKey(%q) returned error: %v", valid, err)
+	}
+
+	invalid := []string{
+		"",
+		"xyz",
+		hex64("04"),
+		hex64("02")[:64],
+		"02" + "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+	}
+	for _, key := range invalid {
+		if err := ValidatePubKey(key); err == nil {
+			t.Errorf("ValidatePubKey(%q) should have returned an error", key)
+		}
+	}
+}
+
+// hex64 returns prefix followed by 32 bytes of hex.
+func hex64(prefix string) string {
+	return prefix + "1a259ee122414f57a63bbd6887ee17960e9106b0adcf89a298cdad2108adf4d9"
+}
+
+// handleBucketKeys serves a bucket whose pubkeys are updated by PATCH.
+func handleBucketKeys(t *testing.T, path string, pubKeys *[]string) {
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PATCH" {
+			var sent struct {
+				PubKeys []string `json:"pubkeys"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+				t.Errorf("received bad JSON")
+			}
+			*pubKeys = sent.PubKeys
+		}
+
+		b := exBucket
+		b.PubKeys = *pubKeys
+		json.NewEncoder(w).Encode(&b)
+	})
+}
+
+func TestBucketsAddPubKey(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	pubKeys := append([]string{}, exBucket.PubKeys...)
+	handleBucketKeys(t, "/buckets/xyz", &pubKeys)
+
+	bucket, err := client.Buckets.AddPubKey(context.Background(), "xyz", otherPubKey)
+	if err != nil {
+		t.Fatalf("Buckets.AddPubKey returned error: %v", err)
+	}
+
+	expected := []string{exBucket.PubKeys[0], otherPubKey}
+	if !reflect.DeepEqual(bucket.PubKeys, expected) {
+		t.Errorf("Buckets.AddPubKey returned pubkeys %v, expected %v", bucket.PubKeys, expected)
+	}
+
+	bucket, err = client.Buckets.AddPubKey(context.Background(), "xyz", strings.ToUpper(otherPubKey))
+	if err != nil || !reflect.DeepEqual(bucket.PubKeys, expected) {
+		t.Errorf("adding a key again in uppercase returned %v, %v, expected %v", bucket, err, expected)
+	}
+
+	if _, err := client.Buckets.AddPubKey(context.Background(), "xyz", "bogus"); err == nil {
+		t.Errorf("Buckets.AddPubKey should reject malformed keys")
+	}
+}
+
+func TestBucketsRemovePubKey(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	pubKeys := []string{exBucket.PubKeys[0], otherPubKey}
+	handleBucketKeys(t, "/buckets/xyz", &pubKeys)
+
+	bucket, err := client.Buckets.RemovePubKey(context.Background(), "xyz", otherPubKey)
+	if err != nil {
+		t.Fatalf("Buckets.RemovePubKey returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(bucket.PubKeys, exBucket.PubKeys) {
+		t.Errorf("Buckets.RemovePubKey returned pubkeys %v, expected %v", bucket.PubKeys, exBucket.PubKeys)
+	}
+}
+
+func TestBucketsRevokePubKey(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	mux.HandleFunc("/buckets", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[
+  {"id": "a", "pubkeys": [%q]},
+  {"id": "b", "pubkeys": []},
+  {"id": "c", "pubkeys": [%q]}]`, otherPubKey, otherPubKey)
+	})
+
+	patched := make(map[string]bool)
+	for _, id := range []string{"a", "b", "c"} {
+		id := id
+		mux.HandleFunc("/buckets/"+id, func(w http.ResponseWriter, r *http.Request) {
+			assertMethod(t, r, "PATCH")
+			if id == "c" {
+				w.WriteHeader(500)
+				return
+			}
+			patched[id] = true
+			fmt.Fprintf(w, `{"id": %q, "pubkeys": []}`, id)
+		})
+	}
+
+	revoked, err := client.Buckets.RevokePubKey(context.Background(), otherPubKey)
+	if err == nil {
+		t.Errorf("Buckets.RevokePubKey should report the failed bucket")
+	}
+	if !reflect.DeepEqual(revoked, []string{"a"}) {
+		t.Errorf("Buckets.RevokePubKey returned %v, expected [a]", revoked)
+	}
+	if patched["b"] {
+		t.Errorf("buckets without the key should not be updated")
+	}
+}
+
+func TestBucketsRevokePubKeyUppercase(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	mux.HandleFunc("/buckets", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"id": "a", "pubkeys": [%q]}]`, otherPubKey)
+	})
+
+	var sent map[string]interface{}
+	mux.HandleFunc("/buckets/a", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "PATCH")
+		json.NewDecoder(r.Body).Decode(&sent)
+		fmt.Fprint(w, `{"id": "a", "pubkeys": []}`)
+	})
+
+	revoked, err := client.Buckets.RevokePubKey(context.Background(), strings.ToUpper(otherPubKey))
+	if err != nil {
+		t.Fatalf("Buckets.RevokePubKey returned error: %v", err)
+	}
+	if !reflect.DeepEqual(revoked, []string{"a"}) {
+		t.Errorf("Buckets.RevokePubKey returned %v, expected [a]", revoked)
+	}
+	if keys, ok := sent["pubkeys"].([]interface{}); !ok || len(keys) != 0 {
+		t.Errorf("sent pubkeys %v, expected none", sent["pubkeys"])
+	}
+}
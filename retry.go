@@ -0,0 +1,162 @@
+package storj
+
+import (
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes how Client.Do retries idempotent requests (GET, HEAD,
+// OPTIONS and DELETE) that fail with a connection error, a 429 or a 5xx.
+// Requests signed with a __nonce are re-signed with a fresh nonce before each
+// retry.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+
+	// MaxElapsed bounds the total time spent on a request, including the
+	// time spent waiting between attempts. Zero means no limit.
+	MaxElapsed time.Duration
+
+	// BaseDelay is the wait before the first retry. It doubles on every
+	// subsequent attempt up to MaxDelay.
+	BaseDelay time.Duration
+
+	// MaxDelay caps every wait, including one asked for by a Retry-After
+	// header. Zero means no cap, in which case Retry-After is obeyed as
+	// given.
+	MaxDelay time.Duration
+
+	// Jitter is the fraction, between 0 and 1, by which each delay is
+	// randomly shortened or lengthened.
+	Jitter float64
+}
+
+// DefaultRetryPolicy is a reasonable policy for talking to the public Bridge.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	MaxElapsed:  30 * time.Second,
+	BaseDelay:   250 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+	Jitter:      0.2,
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "DELETE":
+		return true
+	}
+	return false
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// delay returns how long to wait before the given retry attempt, where the
+// first retry is attempt 1.
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt; i++ {
+		if p.MaxDelay > 0 && d >= p.MaxDelay {
+			break
+		}
+		if d > math.MaxInt64/2 {
+			d = math.MaxInt64
+			break
+		}
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		d += time.Duration(p.Jitter * (2*rand.Float64() - 1) * float64(d))
+	}
+	if d < 0 {
+		d = 0
+	}
+
+	return d
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an
+// HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	h := resp.Header.Get("Retry-After")
+	if h == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(h); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+// send performs req, retrying according to c.RetryPolicy.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	p := c.RetryPolicy
+	if p == nil || p.MaxAttempts <= 1 || !isIdempotent(req.Method) {
+		return c.client.Do(req)
+	}
+
+	ctx := req.Context()
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.client.Do(req)
+		if ctx.Err() != nil || attempt >= p.MaxAttempts {
+			return resp, err
+		}
+		if err == nil && !isRetryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+
+		wait := p.delay(attempt)
+		if resp != nil {
+			if d, ok := retryAfter(resp); ok {
+				wait = d
+				if p.MaxDelay > 0 && wait > p.MaxDelay {
+					wait = p.MaxDelay
+				}
+			}
+		}
+		if p.MaxElapsed > 0 && time.Since(start)+wait > p.MaxElapsed {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxErrorBodySize))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		req = req.Clone(ctx)
+		if req.URL.Query().Get("__nonce") != "" {
+			if err := c.signNonce(req); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package storj
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    5 * time.Millisecond,
+}
+
+func TestRetryResignsRequest(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+	client.RetryPolicy = &testRetryPolicy
+
+	nonces := make(map[string]bool)
+	mux.HandleFunc("/buckets/xyz", func(w http.ResponseWriter, r *http.Request) {
+		nonce := r.URL.Query().Get("__nonce")
+		if nonces[nonce] {
+			t.Errorf("nonce %q was reused", nonce)
+		}
+		nonces[nonce] = true
+
+		if len(nonces) < 3 {
+			w.WriteHeader(503)
+			return
+		}
+		fmt.Fprint(w, bucketJson)
+	})
+
+	_, err := client.Buckets.Get(context.Background(), "xyz")
+	if err != nil {
+		t.Errorf("Buckets.Get returned error: %v", err)
+	}
+	if len(nonces) != 3 {
+		t.Errorf("expected 3 attempts, got %d", len(nonces))
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	setup()
+	defer teardown()
+
+	client.RetryPolicy = &testRetryPolicy
+
+	attempts := 0
+	mux.HandleFunc("/contacts", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(429)
+	})
+
+	_, err := client.Contacts.List(context.Background())
+	if !IsRateLimited(err) {
+		t.Errorf("expected rate limit error, got %v", err)
+	}
+	if attempts != testRetryPolicy.MaxAttempts {
+		t.Errorf("expected %d attempts, got %d", testRetryPolicy.MaxAttempts, attempts)
+	}
+}
+
+func TestRetryCapsRetryAfter(t *testing.T) {
+	setup()
+	defer teardown()
+
+	client.RetryPolicy = &testRetryPolicy
+
+	attempts := 0
+	mux.HandleFunc("/contacts", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "86400")
+			w.WriteHeader(503)
+			return
+		}
+		fmt.Fprint(w, "[]")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := client.Contacts.List(ctx); err != nil {
+		t.Errorf("Contacts.List returned error: %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestRetrySkipsNonIdempotent(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+	client.RetryPolicy = &testRetryPolicy
+
+	attempts := 0
+	mux.HandleFunc("/buckets", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(500)
+	})
+
+	_, err := client.Buckets.New(context.Background(), "test bucket", 1, 1)
+	if err == nil {
+		t.Errorf("Buckets.New should have failed")
+	}
+	if attempts != 1 {
+		t.Errorf("POST should not be retried, got %d attempts", attempts)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"7"}}}
+	if d, ok := retryAfter(resp); !ok || d != 7*time.Second {
+		t.Errorf("retryAfter returned %v, %v", d, ok)
+	}
+
+	resp.Header.Set("Retry-After", "soon")
+	if _, ok := retryAfter(resp); ok {
+		t.Errorf("retryAfter should reject a malformed header")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	capped := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	for attempt, expected := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		if d := capped.delay(attempt); d != expected {
+			t.Errorf("capped delay(%d) = %v, expected %v", attempt, d, expected)
+		}
+	}
+
+	// A zero MaxDelay means no cap, not no growth.
+	unbounded := RetryPolicy{BaseDelay: time.Second}
+	for attempt, expected := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 5: 16 * time.Second} {
+		if d := unbounded.delay(attempt); d != expected {
+			t.Errorf("unbounded delay(%d) = %v, expected %v", attempt, d, expected)
+		}
+	}
+	if d := unbounded.delay(100); d <= 0 {
+		t.Errorf("unbounded delay(100) = %v, expected it not to overflow", d)
+	}
+}
@@ -0,0 +1,131 @@
+package storj
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// GenerateAuthKey returns a new random secp256k1 key pair.
+func GenerateAuthKey() (*btcec.PrivateKey, error) {
+	return btcec.NewPrivateKey(btcec.S256())
+}
+
+// PubKeyHex returns the hex-encoded compressed form of pub, as used by the
+// Bridge to identify keys.
+func PubKeyHex(pub *btcec.PublicKey) string {
+	return hex.EncodeToString(pub.SerializeCompressed())
+}
+
+// rollbackTimeout bounds the cleanup Rotate does after a failure. Cleanup
+// runs on its own context, so it still happens when ctx was canceled.
+const rollbackTimeout = 30 * time.Second
+
+// Rotate replaces Client.AuthKey with a newly generated key. The new key is
+// registered, checked with a signed call, and only then is the old key
+// deleted. If a step fails while the old key is still registered, the client
+// is switched back to the old key and the new key is unregistered, so the
+// account is never left without a working key.
+//
+// Once the new key may have been registered, Rotate returns it even with an
+// error, so a caller can always save it. If deleting the old key fails in a
+// way that leaves it unclear whether the Bridge applied the delete, the old
+// key is looked up again: if it is gone, the rotation succeeded; if the
+// lookup fails, the client keeps the new key and Rotate returns an error
+// saying the old key may still be registered.
+//
+// Rotate changes the client's AuthKey, so no other calls should be made
+// through the same Client while it runs.
+func (s *KeyService) Rotate(ctx context.Context) (*btcec.PrivateKey, error) {
+	c := s.client
+	if c.Signer != nil {
+		return nil, errors.New("cannot rotate a key held by a Signer")
+	}
+
+	oldKey := c.AuthKey
+	if oldKey == nil {
+		return nil, errors.New("authentication required")
+	}
+	oldPub := PubKeyHex(oldKey.PubKey())
+
+	newKey, err := GenerateAuthKey()
+	if err != nil {
+		return nil, err
+	}
+	newPub := PubKeyHex(newKey.PubKey())
+
+	if err := s.Register(ctx, newPub); err != nil {
+		// The Bridge may have registered the key even so.
+		return newKey, fmt.Errorf("registering new key: %w", err)
+	}
+
+	c.AuthKey = newKey
+
+	if err := s.verify(ctx, newPub); err != nil {
+		return newKey, s.rollback(oldKey, newPub, err)
+	}
+
+	err = s.Delete(ctx, oldPub)
+	if err == nil {
+		return newKey, nil
+	}
+	err = fmt.Errorf("deleting old key: %w", err)
+
+	cctx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+	defer cancel()
+
+	present, lerr := s.hasKey(cctx, oldPub)
+	switch {
+	case lerr != nil:
+		return newKey, fmt.Errorf("%v; the old key %s may still be registered: %v", err, oldPub, lerr)
+	case !present:
+		// The delete was applied even though it reported an error.
+		return newKey, nil
+	}
+
+	return newKey, s.rollback(oldKey, newPub, err)
+}
+
+// rollback switches the client back to oldKey and unregisters newPub after
+// a rotation failed with err.
+func (s *KeyService) rollback(oldKey *btcec.PrivateKey, newPub string, err error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+	defer cancel()
+
+	s.client.AuthKey = oldKey
+	if rbErr := s.Delete(ctx, newPub); rbErr != nil && !IsNotFound(rbErr) {
+		return fmt.Errorf("%v; rollback failed, new key %s is still registered: %v", err, newPub, rbErr)
+	}
+	return err
+}
+
+// hasKey reports whether key is registered to the account.
+func (s *KeyService) hasKey(ctx context.Context, key string) (bool, error) {
+	keys, err := s.List(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, k := range keys {
+		if k.Key == key {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// verify makes a signed call and checks that key is registered.
+func (s *KeyService) verify(ctx context.Context, key string) error {
+	present, err := s.hasKey(ctx, key)
+	if err != nil {
+		return fmt.Errorf("verifying new key: %w", err)
+	}
+	if !present {
+		return errors.New("verifying new key: key missing from key list")
+	}
+	return nil
+}
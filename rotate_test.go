@@ -0,0 +1,179 @@
+package storj
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// keyServer is a minimal /keys endpoint that only accepts requests signed
+// by a registered key.
+type keyServer struct {
+	mu         sync.Mutex
+	keys       map[string]bool
+	failDelete bool
+
+	// applyThenFail makes the next DELETE take effect but still report a
+	// failure, as when the response is lost.
+	applyThenFail bool
+
+	// onDelete, if set, is called when a DELETE arrives.
+	onDelete func()
+}
+
+func (ks *keyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if !ks.keys[r.Header.Get("x-pubkey")] {
+		w.WriteHeader(401)
+		return
+	}
+
+	switch {
+	case r.Method == "GET":
+		keys := []Key{}
+		for k := range ks.keys {
+			keys = append(keys, Key{Key: k})
+		}
+		json.NewEncoder(w).Encode(keys)
+
+	case r.Method == "POST":
+		var k Key
+		json.NewDecoder(r.Body).Decode(&k)
+		ks.keys[k.Key] = true
+		json.NewEncoder(w).Encode(k)
+
+	case r.Method == "DELETE":
+		if ks.onDelete != nil {
+			ks.onDelete()
+			ks.onDelete = nil
+		}
+		if ks.applyThenFail {
+			ks.applyThenFail = false
+			delete(ks.keys, strings.TrimPrefix(r.URL.Path, "/keys/"))
+			w.WriteHeader(500)
+			return
+		}
+		if ks.failDelete {
+			ks.failDelete = false
+			w.WriteHeader(500)
+			return
+		}
+		delete(ks.keys, strings.TrimPrefix(r.URL.Path, "/keys/"))
+		w.WriteHeader(204)
+	}
+}
+
+func TestKeysRotate(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	oldPub := PubKeyHex(privKey.PubKey())
+	ks := &keyServer{keys: map[string]bool{oldPub: true}}
+	mux.Handle("/keys", ks)
+	mux.Handle("/keys/", ks)
+
+	newKey, err := client.Keys.Rotate(context.Background())
+	if err != nil {
+		t.Fatalf("Keys.Rotate returned error: %v", err)
+	}
+
+	if client.AuthKey != newKey {
+		t.Errorf("Keys.Rotate did not switch the client to the new key")
+	}
+	newPub := PubKeyHex(newKey.PubKey())
+	if !ks.keys[newPub] || ks.keys[oldPub] || len(ks.keys) != 1 {
+		t.Errorf("expected only the new key to be registered, got %v", ks.keys)
+	}
+}
+
+func TestKeysRotateRollback(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	oldPub := PubKeyHex(privKey.PubKey())
+	ks := &keyServer{keys: map[string]bool{oldPub: true}, failDelete: true}
+	mux.Handle("/keys", ks)
+	mux.Handle("/keys/", ks)
+
+	newKey, err := client.Keys.Rotate(context.Background())
+	if err == nil {
+		t.Fatalf("Keys.Rotate should have failed")
+	}
+	if newKey == nil {
+		t.Errorf("Keys.Rotate should return the new key with the error")
+	}
+
+	if client.AuthKey != privKey {
+		t.Errorf("Keys.Rotate did not restore the old key")
+	}
+	if !ks.keys[oldPub] || len(ks.keys) != 1 {
+		t.Errorf("expected only the old key to be registered, got %v", ks.keys)
+	}
+}
+
+func TestKeysRotateDeleteAppliedDespiteError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	oldPub := PubKeyHex(privKey.PubKey())
+	ks := &keyServer{keys: map[string]bool{oldPub: true}, applyThenFail: true}
+	mux.Handle("/keys", ks)
+	mux.Handle("/keys/", ks)
+
+	newKey, err := client.Keys.Rotate(context.Background())
+	if err != nil {
+		t.Fatalf("Keys.Rotate returned error: %v", err)
+	}
+
+	if client.AuthKey != newKey {
+		t.Errorf("Keys.Rotate switched back to the deleted old key")
+	}
+	if !ks.keys[PubKeyHex(newKey.PubKey())] || len(ks.keys) != 1 {
+		t.Errorf("expected only the new key to be registered, got %v", ks.keys)
+	}
+}
+
+func TestKeysRotateRollbackAfterCancel(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	oldPub := PubKeyHex(privKey.PubKey())
+	ks := &keyServer{keys: map[string]bool{oldPub: true}, failDelete: true, onDelete: cancel}
+	mux.Handle("/keys", ks)
+	mux.Handle("/keys/", ks)
+
+	newKey, err := client.Keys.Rotate(ctx)
+	if err == nil || strings.Contains(err.Error(), "rollback failed") {
+		t.Fatalf("Keys.Rotate should fail and roll back cleanly, got %v", err)
+	}
+	if newKey == nil {
+		t.Errorf("Keys.Rotate should return the new key with the error")
+	}
+
+	if client.AuthKey != privKey {
+		t.Errorf("Keys.Rotate did not restore the old key")
+	}
+	if !ks.keys[oldPub] || len(ks.keys) != 1 {
+		t.Errorf("expected only the old key to be registered, got %v", ks.keys)
+	}
+}
@@ -0,0 +1,38 @@
+package storj
+
+import (
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// Signer produces the x-pubkey and x-signature headers for signed requests.
+// Implementations may keep the private key outside the process entirely.
+type Signer interface {
+	// PublicKey returns the public half of the signing key.
+	PublicKey() *btcec.PublicKey
+
+	// Sign returns the DER-encoded ECDSA signature of a SHA-256 digest.
+	Sign(digest []byte) ([]byte, error)
+}
+
+// NewKeySigner returns a Signer backed by an in-memory private key. It is
+// what Client uses for AuthKey when no Signer is set.
+func NewKeySigner(key *btcec.PrivateKey) Signer {
+	return keySigner{key}
+}
+
+type keySigner struct {
+	key *btcec.PrivateKey
+}
+
+func (s keySigner) PublicKey() *btcec.PublicKey {
+	return s.key.PubKey()
+}
+
+func (s keySigner) Sign(digest []byte) ([]byte, error) {
+	sig, err := s.key.Sign(digest)
+	if err != nil {
+		return nil, err
+	}
+
+	return sig.Serialize(), nil
+}
@@ -0,0 +1,80 @@
+package storj
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+type testSigner struct {
+	key     *btcec.PrivateKey
+	digests [][]byte
+	err     error
+}
+
+func (s *testSigner) PublicKey() *btcec.PublicKey {
+	return s.key.PubKey()
+}
+
+func (s *testSigner) Sign(digest []byte) ([]byte, error) {
+	s.digests = append(s.digests, digest)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return NewKeySigner(s.key).Sign(digest)
+}
+
+func TestSigner(t *testing.T) {
+	setup()
+	defer teardown()
+
+	signer := &testSigner{key: privKey}
+	client.Signer = signer
+
+	pubKey := hex.EncodeToString(privKey.PubKey().SerializeCompressed())
+
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		assertHeader(t, r, "x-pubkey", pubKey)
+
+		sigBytes, err := hex.DecodeString(r.Header.Get("x-signature"))
+		if err != nil {
+			t.Fatalf("bad signature encoding: %v", err)
+		}
+		sig, err := btcec.ParseDERSignature(sigBytes, btcec.S256())
+		if err != nil {
+			t.Fatalf("bad signature: %v", err)
+		}
+
+		msg := fmt.Sprintf("GET\n/keys\n__nonce=%s", r.URL.Query().Get("__nonce"))
+		sha := sha256.Sum256([]byte(msg))
+		if !sig.Verify(sha[:], privKey.PubKey()) {
+			t.Errorf("signature does not verify")
+		}
+		fmt.Fprint(w, "[]")
+	})
+
+	if _, err := client.Keys.List(context.Background()); err != nil {
+		t.Errorf("Keys.List returned error: %v", err)
+	}
+	if len(signer.digests) != 1 {
+		t.Errorf("expected 1 signed digest, got %d", len(signer.digests))
+	}
+}
+
+func TestSignerError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	client.Signer = &testSigner{key: privKey, err: errors.New("signer unavailable")}
+
+	_, err := client.Keys.List(context.Background())
+	if err == nil || err.Error() != "signer unavailable" {
+		t.Errorf("expected signer error, got %v", err)
+	}
+}
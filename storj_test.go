@@ -3,7 +3,6 @@ package storj
 import (
 	"net/http"
 	"net/http/httptest"
-	"net/url"
 	"testing"
 
 	"github.com/btcsuite/btcd/btcec"
@@ -30,9 +29,11 @@ func setup() {
 	mux = http.NewServeMux()
 	server = httptest.NewServer(mux)
 
-	client = NewClient()
-	url, _ := url.Parse(server.URL)
-	client.BaseURL = url
+	var err error
+	client, err = NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		panic(err)
+	}
 }
 
 func teardown() {
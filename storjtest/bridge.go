@@ -0,0 +1,515 @@
+package storjtest
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mlayne/storj"
+)
+
+func (s *Server) bucket(r *request) (*storj.Bucket, error) {
+	b, ok := s.buckets[r.args[0]]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, "Bucket not found")
+	}
+	if b.User == r.user {
+		return b, nil
+	}
+	for _, k := range b.PubKeys {
+		if r.pubKey != "" && k == r.pubKey {
+			return b, nil
+		}
+	}
+	return nil, errorf(http.StatusNotFound, "Bucket not found")
+}
+
+// ownedBucket is like bucket but only allows the bucket's owner.
+func (s *Server) ownedBucket(r *request) (*storj.Bucket, error) {
+	b, err := s.bucket(r)
+	if err != nil {
+		return nil, err
+	}
+	if b.User != r.user {
+		return nil, errorf(http.StatusForbidden, "Only the bucket owner can do that")
+	}
+	return b, nil
+}
+
+func (s *Server) listBuckets(r *request) (int, interface{}, error) {
+	buckets := []storj.Bucket{}
+	for _, b := range s.buckets {
+		if b.User == r.user {
+			buckets = append(buckets, *b)
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Created.Before(buckets[j].Created)
+	})
+
+	return http.StatusOK, buckets, nil
+}
+
+func (s *Server) createBucket(r *request) (int, interface{}, error) {
+	var params struct {
+		Name     string   `json:"name"`
+		Storage  int      `json:"storage"`
+		Transfer int      `json:"transfer"`
+		PubKeys  []string `json:"pubkeys"`
+	}
+	if err := r.decode(&params); err != nil {
+		return 0, nil, err
+	}
+
+	b := &storj.Bucket{
+		ID:       newID(),
+		Name:     params.Name,
+		User:     r.user,
+		PubKeys:  params.PubKeys,
+		Status:   storj.BucketStatusActive,
+		Created:  time.Now().UTC(),
+		Storage:  params.Storage,
+		Transfer: params.Transfer,
+	}
+	if b.PubKeys == nil {
+		b.PubKeys = []string{}
+	}
+	s.buckets[b.ID] = b
+
+	return http.StatusOK, b, nil
+}
+
+func (s *Server) getBucket(r *request) (int, interface{}, error) {
+	b, err := s.bucket(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, b, nil
+}
+
+func (s *Server) updateBucket(r *request) (int, interface{}, error) {
+	b, err := s.ownedBucket(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var params struct {
+		Name     *string   `json:"name"`
+		Storage  *int      `json:"storage"`
+		Transfer *int      `json:"transfer"`
+		PubKeys  *[]string `json:"pubkeys"`
+	}
+	if err := r.decode(&params); err != nil {
+		return 0, nil, err
+	}
+
+	if params.Name != nil {
+		b.Name = *params.Name
+	}
+	if params.Storage != nil {
+		b.Storage = *params.Storage
+	}
+	if params.Transfer != nil {
+		b.Transfer = *params.Transfer
+	}
+	if params.PubKeys != nil {
+		b.PubKeys = append([]string{}, *params.PubKeys...)
+	}
+
+	return http.StatusOK, b, nil
+}
+
+func (s *Server) deleteBucket(r *request) (int, interface{}, error) {
+	b, err := s.ownedBucket(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	delete(s.buckets, b.ID)
+	for id, f := range s.files {
+		if f.Bucket == b.ID {
+			delete(s.files, id)
+		}
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+func (s *Server) listFiles(r *request) (int, interface{}, error) {
+	b, err := s.bucket(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	files := []storj.File{}
+	for _, f := range s.files {
+		if f.Bucket == b.ID {
+			files = append(files, *f)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+
+	return http.StatusOK, files, nil
+}
+
+// createFile adds a file made of a frame's shards to a bucket. The caller
+// must be signed in and also hold a PUSH token for the bucket.
+func (s *Server) createFile(r *request) (int, interface{}, error) {
+	b, err := s.bucket(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	t, ok := s.tokens[r.Header.Get("x-token")]
+	if !ok || t.Bucket != b.ID || t.Operation != "PUSH" || time.Now().After(t.Expires) {
+		return 0, nil, errorf(http.StatusUnauthorized, "Invalid token")
+	}
+
+	var params struct {
+		Frame    string `json:"frame"`
+		MimeType string `json:"mimetype"`
+		Name     string `json:"filename"`
+		Index    string `json:"index"`
+	}
+	if err := r.decode(&params); err != nil {
+		return 0, nil, err
+	}
+	if params.Name == "" {
+		return 0, nil, errorf(http.StatusBadRequest, "Filename is required")
+	}
+
+	fr, ok := s.frames[params.Frame]
+	if !ok || fr.User != r.user {
+		return 0, nil, errorf(http.StatusNotFound, "Frame not found")
+	}
+	for _, sh := range fr.Shards {
+		if _, ok := s.shards[sh.Hash]; !ok {
+			return 0, nil, errorf(http.StatusBadRequest, "Shard %d was never stored", sh.Index)
+		}
+	}
+
+	// Like the Bridge, derive the ID from the name.
+	id := storj.FileID(b.ID, params.Name)
+	if _, ok := s.files[id]; ok {
+		return 0, nil, errorf(http.StatusConflict, "Name already used by another file")
+	}
+	fr.Locked = true
+
+	f := &storj.File{
+		ID:       id,
+		Bucket:   b.ID,
+		MimeType: params.MimeType,
+		Name:     params.Name,
+		Size:     fr.Size,
+		Frame:    fr.ID,
+		Index:    params.Index,
+	}
+	s.files[f.ID] = f
+
+	return http.StatusOK, f, nil
+}
+
+func (s *Server) fileInfo(r *request) (int, interface{}, error) {
+	b, err := s.bucket(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	f, ok := s.files[r.args[1]]
+	if !ok || f.Bucket != b.ID {
+		return 0, nil, errorf(http.StatusNotFound, "File not found")
+	}
+
+	return http.StatusOK, f, nil
+}
+
+func (s *Server) deleteFile(r *request) (int, interface{}, error) {
+	b, err := s.bucket(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	f, ok := s.files[r.args[1]]
+	if !ok || f.Bucket != b.ID {
+		return 0, nil, errorf(http.StatusNotFound, "File not found")
+	}
+	delete(s.files, f.ID)
+
+	return http.StatusNoContent, nil, nil
+}
+
+// listPointers is authorized by a PULL token rather than a signature.
+func (s *Server) listPointers(r *request) (int, interface{}, error) {
+	bucketID, fileID := r.args[0], r.args[1]
+
+	t, ok := s.tokens[r.Header.Get("x-token")]
+	if !ok || t.Bucket != bucketID || t.Operation != "PULL" || time.Now().After(t.Expires) {
+		return 0, nil, errorf(http.StatusUnauthorized, "Invalid token")
+	}
+
+	f, ok := s.files[fileID]
+	if !ok || f.Bucket != bucketID {
+		return 0, nil, errorf(http.StatusNotFound, "File not found")
+	}
+
+	var shards []storj.Shard
+	if fr, ok := s.frames[f.Frame]; ok {
+		shards = append(shards, fr.Shards...)
+	}
+	sort.Slice(shards, func(i, j int) bool {
+		return shards[i].Index < shards[j].Index
+	})
+
+	// Like the Bridge, return the page of pointers the query asks for.
+	skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+	if skip < 0 || skip > len(shards) {
+		skip = len(shards)
+	}
+	shards = shards[skip:]
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit >= 0 && limit < len(shards) {
+		shards = shards[:limit]
+	}
+
+	pointers := []storj.FilePointer{}
+	for _, sh := range shards {
+		p := storj.FilePointer{
+			Index:     sh.Index,
+			Hash:      sh.Hash,
+			Size:      sh.Size,
+			Token:     randomHex(32),
+			Operation: "PULL",
+			Farmer:    s.farmer(),
+		}
+		s.shardTokens[p.Token] = p
+		pointers = append(pointers, p)
+	}
+
+	return http.StatusOK, pointers, nil
+}
+
+func (s *Server) createToken(r *request) (int, interface{}, error) {
+	b, err := s.bucket(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var params struct {
+		Operation string `json:"operation"`
+	}
+	if err := r.decode(&params); err != nil {
+		return 0, nil, err
+	}
+	if params.Operation != "PULL" && params.Operation != "PUSH" {
+		return 0, nil, errorf(http.StatusBadRequest, "Invalid operation")
+	}
+
+	t := &storj.Token{
+		Token:     randomHex(32),
+		Bucket:    b.ID,
+		Expires:   time.Now().Add(5 * time.Minute).UTC(),
+		Operation: params.Operation,
+	}
+	s.tokens[t.Token] = t
+
+	return http.StatusCreated, t, nil
+}
+
+func (s *Server) listKeys(r *request) (int, interface{}, error) {
+	keys := []storj.Key{}
+	for k, user := range s.keys {
+		if user == r.user {
+			keys = append(keys, storj.Key{Key: k, User: user})
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Key < keys[j].Key
+	})
+
+	return http.StatusOK, keys, nil
+}
+
+func (s *Server) registerKey(r *request) (int, interface{}, error) {
+	var params struct {
+		Key string `json:"key"`
+	}
+	if err := r.decode(&params); err != nil {
+		return 0, nil, err
+	}
+	if user, ok := s.keys[params.Key]; ok && user != r.user {
+		return 0, nil, errorf(http.StatusBadRequest, "Public key is already registered")
+	}
+
+	s.keys[params.Key] = r.user
+
+	return http.StatusCreated, storj.Key{Key: params.Key, User: r.user}, nil
+}
+
+func (s *Server) deleteKey(r *request) (int, interface{}, error) {
+	if s.keys[r.args[0]] != r.user {
+		return 0, nil, errorf(http.StatusNotFound, "Public key was not found")
+	}
+	delete(s.keys, r.args[0])
+
+	return http.StatusNoContent, nil, nil
+}
+
+func (s *Server) listContacts(r *request) (int, interface{}, error) {
+	contacts := []storj.Contact{}
+	for _, c := range s.contacts {
+		contacts = append(contacts, c)
+	}
+	sort.Slice(contacts, func(i, j int) bool {
+		return contacts[i].NodeID < contacts[j].NodeID
+	})
+
+	return http.StatusOK, contacts, nil
+}
+
+func (s *Server) getContact(r *request) (int, interface{}, error) {
+	c, ok := s.contacts[r.args[0]]
+	if !ok {
+		return 0, nil, errorf(http.StatusNotFound, "Contact not found")
+	}
+
+	return http.StatusOK, c, nil
+}
+
+func (s *Server) frame(r *request) (*storj.Frame, error) {
+	f, ok := s.frames[r.args[0]]
+	if !ok || f.User != r.user {
+		return nil, errorf(http.StatusNotFound, "Frame not found")
+	}
+	return f, nil
+}
+
+func (s *Server) listFrames(r *request) (int, interface{}, error) {
+	frames := []storj.Frame{}
+	for _, f := range s.frames {
+		if f.User == r.user {
+			frames = append(frames, *f)
+		}
+	}
+	sort.Slice(frames, func(i, j int) bool {
+		return frames[i].Created.Before(frames[j].Created)
+	})
+
+	return http.StatusOK, frames, nil
+}
+
+func (s *Server) createFrame(r *request) (int, interface{}, error) {
+	f := &storj.Frame{
+		ID:      newID(),
+		User:    r.user,
+		Created: time.Now().UTC(),
+		Shards:  []storj.Shard{},
+	}
+	s.frames[f.ID] = f
+
+	return http.StatusOK, f, nil
+}
+
+func (s *Server) getFrame(r *request) (int, interface{}, error) {
+	f, err := s.frame(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, f, nil
+}
+
+func (s *Server) deleteFrame(r *request) (int, interface{}, error) {
+	f, err := s.frame(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	delete(s.frames, f.ID)
+
+	return http.StatusNoContent, nil, nil
+}
+
+// addShard records a shard in a frame and assigns it to the server's farmer.
+func (s *Server) addShard(r *request) (int, interface{}, error) {
+	f, err := s.frame(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if f.Locked {
+		return 0, nil, errorf(http.StatusBadRequest, "Frame is locked")
+	}
+
+	var sh storj.Shard
+	if err := r.decode(&sh); err != nil {
+		return 0, nil, err
+	}
+	if sh.Hash == "" {
+		return 0, nil, errorf(http.StatusBadRequest, "Shard hash is required")
+	}
+
+	replaced := false
+	for i := range f.Shards {
+		if f.Shards[i].Index == sh.Index {
+			f.Size += sh.Size - f.Shards[i].Size
+			f.Shards[i] = sh
+			replaced = true
+		}
+	}
+	if !replaced {
+		f.Shards = append(f.Shards, sh)
+		f.Size += sh.Size
+	}
+	sort.Slice(f.Shards, func(i, j int) bool {
+		return f.Shards[i].Index < f.Shards[j].Index
+	})
+
+	p := storj.FilePointer{
+		Hash:      sh.Hash,
+		Token:     randomHex(32),
+		Operation: "PUSH",
+		Farmer:    s.farmer(),
+	}
+	s.shardTokens[p.Token] = p
+
+	return http.StatusOK, p, nil
+}
+
+// storeShard plays the farmer's part of an upload, accepting shard data
+// authorized by a token from addShard.
+func (s *Server) storeShard(r *request) (int, interface{}, error) {
+	hash := r.args[0]
+	if !s.shardToken(r, "PUSH", hash) {
+		return 0, nil, errorf(http.StatusUnauthorized, "Invalid token")
+	}
+	if storj.ShardHash(r.body) != hash {
+		return 0, nil, errorf(http.StatusBadRequest, "Shard hash does not match data")
+	}
+	s.shards[hash] = r.body
+
+	return http.StatusOK, map[string]string{"result": "The shard was stored"}, nil
+}
+
+// retrieveShard plays the farmer's part of a download, serving shard data
+// authorized by a token from listPointers. It writes the raw data rather
+// than JSON, so it bypasses the usual response encoding.
+func (s *Server) retrieveShard(r *request) (int, interface{}, error) {
+	hash := r.args[0]
+	if !s.shardToken(r, "PULL", hash) {
+		return 0, nil, errorf(http.StatusUnauthorized, "Invalid token")
+	}
+	data, ok := s.shards[hash]
+	if !ok {
+		return 0, nil, errorf(http.StatusNotFound, "Shard data not found")
+	}
+
+	return http.StatusOK, rawBody(data), nil
+}
+
+// shardToken reports whether r carries a token for operation on the shard
+// with the given hash.
+func (s *Server) shardToken(r *request, operation, hash string) bool {
+	p, ok := s.shardTokens[r.URL.Query().Get("token")]
+	return ok && p.Operation == operation && p.Hash == hash
+}
@@ -0,0 +1,382 @@
+// Package storjtest provides an in-process fake Bridge for testing code that
+// uses the storj client.
+//
+// The fake keeps all state in memory and implements the same authentication
+// rules as the real Bridge: requests must be signed by a registered key (or
+// use basic auth for a registered user), signatures are checked against the
+// canonical "METHOD\npath\nparams" message, and nonces cannot be replayed.
+package storjtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/mlayne/storj"
+)
+
+// Server is a fake Bridge listening on a local port.
+type Server struct {
+	// URL is the base URL of the fake Bridge, suitable for
+	// storj.WithBaseURL.
+	URL string
+
+	srv    *httptest.Server
+	routes []route
+
+	mu       sync.Mutex
+	users    map[string]string // email to hashed password
+	keys     map[string]string // hex public key to email
+	nonces   map[string]bool
+	buckets  map[string]*storj.Bucket
+	files    map[string]*storj.File
+	tokens   map[string]*storj.Token
+	frames   map[string]*storj.Frame
+	contacts map[string]storj.Contact
+
+	// shards holds shard data by hash, and shardTokens the pointers handed
+	// out by token, which a farmer checks before accepting or serving one.
+	shards      map[string][]byte
+	shardTokens map[string]storj.FilePointer
+}
+
+// NewServer starts a fake Bridge. Callers should Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		users:    make(map[string]string),
+		keys:     make(map[string]string),
+		nonces:   make(map[string]bool),
+		buckets:  make(map[string]*storj.Bucket),
+		files:    make(map[string]*storj.File),
+		tokens:   make(map[string]*storj.Token),
+		frames:   make(map[string]*storj.Frame),
+		contacts: make(map[string]storj.Contact),
+
+		shards:      make(map[string][]byte),
+		shardTokens: make(map[string]storj.FilePointer),
+	}
+	s.routes = s.newRoutes()
+	s.srv = httptest.NewServer(s)
+	s.URL = s.srv.URL
+
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// NewClient returns a client pointed at the server and signing with key,
+// registering key to user if it is not registered yet.
+func (s *Server) NewClient(user string, key *btcec.PrivateKey, opts ...storj.Option) (*storj.Client, error) {
+	s.AddKey(user, key.PubKey())
+
+	opts = append([]storj.Option{storj.WithBaseURL(s.URL), storj.WithAuthKey(key)}, opts...)
+	return storj.NewClient(opts...)
+}
+
+// AddUser registers a user who can authenticate with basic auth.
+func (s *Server) AddUser(email, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[email] = storj.HashPassword(password)
+}
+
+// AddKey registers pub as one of user's keys.
+func (s *Server) AddKey(user string, pub *btcec.PublicKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[storj.PubKeyHex(pub)] = user
+}
+
+// AddFile stores a file entry in a bucket, assigning it an ID if it has none.
+func (s *Server) AddFile(bucketID string, f storj.File) storj.File {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f.ID == "" {
+		f.ID = newID()
+	}
+	f.Bucket = bucketID
+	s.files[f.ID] = &f
+
+	return f
+}
+
+// AddContact makes a farmer visible through /contacts.
+func (s *Server) AddContact(c storj.Contact) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.contacts[c.NodeID] = c
+}
+
+// Shard returns the data a farmer holds for a shard hash.
+func (s *Server) Shard(hash string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.shards[hash]
+	return data, ok
+}
+
+// Bucket returns the server's copy of a bucket.
+func (s *Server) Bucket(id string) (storj.Bucket, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[id]
+	if !ok {
+		return storj.Bucket{}, false
+	}
+	return *b, true
+}
+
+// newID returns a random ID shaped like the Bridge's MongoDB object IDs.
+func newID() string {
+	return randomHex(12)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// httpError is returned by handlers to produce a Bridge-style error body.
+type httpError struct {
+	code int
+	msg  string
+}
+
+func (e *httpError) Error() string {
+	return e.msg
+}
+
+func errorf(code int, format string, args ...interface{}) error {
+	return &httpError{code, fmt.Sprintf(format, args...)}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	if e, ok := err.(*httpError); ok {
+		code = e.code
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// request is an incoming call after its body has been read and, for
+// authenticated routes, its caller identified.
+type request struct {
+	*http.Request
+	body   []byte
+	user   string
+	pubKey string
+	args   []string
+}
+
+func (r *request) decode(v interface{}) error {
+	if err := json.Unmarshal(r.body, v); err != nil {
+		return errorf(http.StatusBadRequest, "invalid JSON body: %v", err)
+	}
+	return nil
+}
+
+// handler serves a route, returning the status code and a value to encode
+// as JSON, or a rawBody to send as is.
+type handler func(r *request) (int, interface{}, error)
+
+// rawBody is a handler result written without JSON encoding.
+type rawBody []byte
+
+type route struct {
+	method  string
+	pattern []string
+	public  bool
+	handler handler
+}
+
+// match reports whether path matches the route's pattern, returning the
+// values of its ":param" segments.
+func (rt *route) match(method string, segs []string) ([]string, bool) {
+	if method != rt.method || len(segs) != len(rt.pattern) {
+		return nil, false
+	}
+
+	var args []string
+	for i, p := range rt.pattern {
+		switch {
+		case strings.HasPrefix(p, ":"):
+			args = append(args, segs[i])
+		case p != segs[i]:
+			return nil, false
+		}
+	}
+
+	return args, true
+}
+
+func (s *Server) newRoutes() []route {
+	return []route{
+		{"GET", []string{"buckets"}, false, s.listBuckets},
+		{"POST", []string{"buckets"}, false, s.createBucket},
+		{"GET", []string{"buckets", ":id"}, false, s.getBucket},
+		{"PATCH", []string{"buckets", ":id"}, false, s.updateBucket},
+		{"DELETE", []string{"buckets", ":id"}, false, s.deleteBucket},
+		{"GET", []string{"buckets", ":id", "files"}, false, s.listFiles},
+		{"POST", []string{"buckets", ":id", "files"}, false, s.createFile},
+		{"GET", []string{"buckets", ":id", "files", ":file"}, true, s.listPointers},
+		{"DELETE", []string{"buckets", ":id", "files", ":file"}, false, s.deleteFile},
+		{"GET", []string{"buckets", ":id", "files", ":file", "info"}, false, s.fileInfo},
+		{"POST", []string{"buckets", ":id", "tokens"}, false, s.createToken},
+		{"GET", []string{"keys"}, false, s.listKeys},
+		{"POST", []string{"keys"}, false, s.registerKey},
+		{"DELETE", []string{"keys", ":key"}, false, s.deleteKey},
+		{"GET", []string{"contacts"}, true, s.listContacts},
+		{"GET", []string{"contacts", ":node"}, true, s.getContact},
+		{"GET", []string{"frames"}, false, s.listFrames},
+		{"POST", []string{"frames"}, false, s.createFrame},
+		{"GET", []string{"frames", ":frame"}, false, s.getFrame},
+		{"DELETE", []string{"frames", ":frame"}, false, s.deleteFrame},
+		{"PUT", []string{"frames", ":frame"}, false, s.addShard},
+		{"POST", []string{"shards", ":hash"}, true, s.storeShard},
+		{"GET", []string{"shards", ":hash"}, true, s.retrieveShard},
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, errorf(http.StatusBadRequest, "reading body: %v", err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	segs := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	for _, rt := range s.routes {
+		args, ok := rt.match(r.Method, segs)
+		if !ok {
+			continue
+		}
+
+		req := &request{Request: r, body: body, args: args}
+		if !rt.public {
+			req.user, req.pubKey, err = s.authenticate(r, body)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			// A key shared on a bucket belongs to no user and may only
+			// reach routes under that bucket's ID.
+			if req.user == "" && (segs[0] != "buckets" || len(segs) < 2) {
+				writeError(w, errorf(http.StatusUnauthorized, "Invalid public key supplied"))
+				return
+			}
+		}
+
+		code, v, err := rt.handler(req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		switch v := v.(type) {
+		case nil:
+			w.WriteHeader(code)
+		case rawBody:
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.WriteHeader(code)
+			w.Write(v)
+		default:
+			writeJSON(w, code, v)
+		}
+		return
+	}
+
+	writeError(w, errorf(http.StatusNotFound, "Not found"))
+}
+
+// authenticate identifies the user behind r from its signature or basic auth
+// credentials. The public key is empty for basic auth.
+//
+// A key listed in some bucket's pubkeys is accepted even if it is not
+// registered to any user; such a caller can only reach the buckets that list
+// it.
+func (s *Server) authenticate(r *http.Request, body []byte) (user, pubKey string, err error) {
+	if email, password, ok := r.BasicAuth(); ok {
+		if hash, ok := s.users[email]; ok && hash == password {
+			return email, "", nil
+		}
+		return "", "", errorf(http.StatusUnauthorized, "Invalid email or password")
+	}
+
+	if r.Header.Get("x-pubkey") == "" {
+		return "", "", errorf(http.StatusUnauthorized, "No authentication strategy detected")
+	}
+
+	pubKey, nonce, err := storj.VerifyRequest(r, body)
+	if err != nil {
+		return "", "", errorf(http.StatusUnauthorized, "Invalid signature: %v", err)
+	}
+
+	user, ok := s.keys[pubKey]
+	if !ok && !s.sharedKey(pubKey) {
+		return "", "", errorf(http.StatusUnauthorized, "Invalid public key supplied")
+	}
+	if s.nonces[nonce] {
+		return "", "", errorf(http.StatusUnauthorized, "Invalid nonce supplied")
+	}
+	s.nonces[nonce] = true
+
+	return user, pubKey, nil
+}
+
+func (s *Server) sharedKey(pubKey string) bool {
+	for _, b := range s.buckets {
+		for _, k := range b.PubKeys {
+			if k == pubKey {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// farmer describes the server itself, which stands in for every farmer.
+func (s *Server) farmer() storj.Farmer {
+	host, port, _ := net.SplitHostPort(s.srv.Listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+
+	return storj.Farmer{
+		Address:  host,
+		Port:     p,
+		NodeID:   "0000000000000000000000000000000000000000",
+		LastSeen: time.Now().UnixNano() / int64(time.Millisecond),
+		Protocol: "0.7.0",
+	}
+}
@@ -0,0 +1,466 @@
+package storjtest
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/mlayne/storj"
+)
+
+const testUser = "gordon@storj.io"
+
+func newTestClient(t *testing.T, s *Server, opts ...storj.Option) *storj.Client {
+	key, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := s.NewClient(testUser, key, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestServerBuckets(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	c := newTestClient(t, s)
+	ctx := context.Background()
+
+	b, err := c.Buckets.New(ctx, "test bucket", 10, 20)
+	if err != nil {
+		t.Fatalf("Buckets.New returned error: %v", err)
+	}
+	if b.Name != "test bucket" || b.User != testUser || b.Storage != 10 || b.Transfer != 20 {
+		t.Errorf("Buckets.New returned %+v", b)
+	}
+
+	got, err := c.Buckets.Get(ctx, b.ID)
+	if err != nil {
+		t.Fatalf("Buckets.Get returned error: %v", err)
+	}
+	if got.ID != b.ID {
+		t.Errorf("Buckets.Get returned bucket %q, expected %q", got.ID, b.ID)
+	}
+
+	name := "renamed"
+	storage := 30
+	b, err = c.Buckets.Update(ctx, b.ID, storj.BucketUpdate{Name: &name, Storage: &storage})
+	if err != nil {
+		t.Fatalf("Buckets.Update returned error: %v", err)
+	}
+	if b.Name != "renamed" || b.Storage != 30 || b.Transfer != 20 {
+		t.Errorf("Buckets.Update returned %+v", b)
+	}
+
+	buckets, err := c.Buckets.List(ctx)
+	if err != nil || len(buckets) != 1 {
+		t.Errorf("Buckets.List returned %v, %v", buckets, err)
+	}
+
+	if err := c.Buckets.Delete(ctx, b.ID); err != nil {
+		t.Errorf("Buckets.Delete returned error: %v", err)
+	}
+	if _, err := c.Buckets.Get(ctx, b.ID); !storj.IsNotFound(err) {
+		t.Errorf("expected not found after delete, got %v", err)
+	}
+}
+
+func TestServerIsolatesUsers(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	ctx := context.Background()
+	b, err := newTestClient(t, s).Buckets.New(ctx, "mine", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := btcec.NewPrivateKey(btcec.S256())
+	oc, _ := s.NewClient("someone@storj.io", other)
+	if _, err := oc.Buckets.Get(ctx, b.ID); !storj.IsNotFound(err) {
+		t.Errorf("another user should not see the bucket, got %v", err)
+	}
+}
+
+func TestServerFiles(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	c := newTestClient(t, s)
+	ctx := context.Background()
+
+	b, _ := c.Buckets.New(ctx, "files", 1, 1)
+	f := s.AddFile(b.ID, storj.File{Name: "a.txt", MimeType: "text/plain", Size: 3})
+
+	files, err := c.Files.List(ctx, b.ID)
+	if err != nil || len(files) != 1 || files[0].ID != f.ID {
+		t.Errorf("Files.List returned %+v, %v", files, err)
+	}
+
+	token, err := c.Tokens.New(ctx, "PULL", b.ID)
+	if err != nil {
+		t.Fatalf("Tokens.New returned error: %v", err)
+	}
+	if _, err := c.Files.ListPointers(ctx, b.ID, f.ID, token.Token); err != nil {
+		t.Errorf("Files.ListPointers returned error: %v", err)
+	}
+	if _, err := c.Files.ListPointers(ctx, b.ID, f.ID, "bogus"); !storj.IsUnauthorized(err) {
+		t.Errorf("Files.ListPointers should reject a bad token, got %v", err)
+	}
+
+	if err := c.Files.Delete(ctx, b.ID, f.ID); err != nil {
+		t.Errorf("Files.Delete returned error: %v", err)
+	}
+	if err := c.Files.Delete(ctx, b.ID, f.ID); !storj.IsNotFound(err) {
+		t.Errorf("expected not found deleting twice, got %v", err)
+	}
+}
+
+func TestServerKeys(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	c := newTestClient(t, s)
+	ctx := context.Background()
+
+	newKey, _ := btcec.NewPrivateKey(btcec.S256())
+	pub := storj.PubKeyHex(newKey.PubKey())
+	if err := c.Keys.Register(ctx, pub); err != nil {
+		t.Fatalf("Keys.Register returned error: %v", err)
+	}
+
+	keys, err := c.Keys.List(ctx)
+	if err != nil || len(keys) != 2 {
+		t.Errorf("Keys.List returned %v, %v", keys, err)
+	}
+
+	if err := c.Keys.Delete(ctx, pub); err != nil {
+		t.Errorf("Keys.Delete returned error: %v", err)
+	}
+}
+
+func TestServerBasicAuth(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	s.AddUser(testUser, "hunter2")
+	ctx := context.Background()
+
+	c, _ := storj.NewClient(storj.WithBaseURL(s.URL), storj.WithBasicAuth(testUser, "hunter2"))
+	if _, err := c.Keys.List(ctx); err != nil {
+		t.Errorf("Keys.List returned error: %v", err)
+	}
+
+	c, _ = storj.NewClient(storj.WithBaseURL(s.URL), storj.WithBasicAuth(testUser, "wrong"))
+	if _, err := c.Keys.List(ctx); !storj.IsUnauthorized(err) {
+		t.Errorf("expected unauthorized, got %v", err)
+	}
+}
+
+// replayTransport sends every request twice and returns the second response.
+type replayTransport struct{}
+
+func (replayTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	var body []byte
+	if r.Body != nil {
+		body, _ = ioutil.ReadAll(r.Body)
+	}
+
+	first := r.Clone(r.Context())
+	first.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp, err := http.DefaultTransport.RoundTrip(first)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestServerRejectsReplay(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	key, _ := btcec.NewPrivateKey(btcec.S256())
+	c, _ := s.NewClient(testUser, key, storj.WithHTTPClient(&http.Client{Transport: replayTransport{}}))
+	ctx := context.Background()
+
+	if _, err := c.Buckets.List(ctx); !storj.IsUnauthorized(err) {
+		t.Errorf("replayed GET should be rejected, got %v", err)
+	}
+	if _, err := c.Buckets.New(ctx, "replayed", 1, 1); !storj.IsUnauthorized(err) {
+		t.Errorf("replayed POST should be rejected, got %v", err)
+	}
+}
+
+func TestServerRejectsUnknownKey(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	key, _ := btcec.NewPrivateKey(btcec.S256())
+	c, _ := storj.NewClient(storj.WithBaseURL(s.URL), storj.WithAuthKey(key))
+
+	if _, err := c.Buckets.List(context.Background()); !storj.IsUnauthorized(err) {
+		t.Errorf("unregistered key should be rejected, got %v", err)
+	}
+}
+
+func TestServerSharedBucket(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	c := newTestClient(t, s)
+	ctx := context.Background()
+
+	b, _ := c.Buckets.New(ctx, "shared", 1, 1)
+
+	// A service account key that belongs to no user.
+	shared, _ := btcec.NewPrivateKey(btcec.S256())
+	sc, _ := storj.NewClient(storj.WithBaseURL(s.URL), storj.WithAuthKey(shared))
+	sharedPub := storj.PubKeyHex(shared.PubKey())
+
+	if _, err := c.Buckets.AddPubKey(ctx, b.ID, sharedPub); err != nil {
+		t.Fatalf("Buckets.AddPubKey returned error: %v", err)
+	}
+	if _, err := sc.Files.List(ctx, b.ID); err != nil {
+		t.Errorf("shared key should be able to list files, got %v", err)
+	}
+	if err := sc.Buckets.Delete(ctx, b.ID); !storj.IsUnauthorized(err) {
+		t.Errorf("shared key should not be able to delete the bucket, got %v", err)
+	}
+	if _, err := sc.Keys.List(ctx); !storj.IsUnauthorized(err) {
+		t.Errorf("shared key should not be able to list keys, got %v", err)
+	}
+	if _, err := sc.Buckets.List(ctx); !storj.IsUnauthorized(err) {
+		t.Errorf("shared key should not be able to list buckets, got %v", err)
+	}
+	if _, err := sc.Buckets.New(ctx, "ownerless", 1, 1); !storj.IsUnauthorized(err) {
+		t.Errorf("shared key should not be able to create a bucket, got %v", err)
+	}
+
+	revoked, err := c.Buckets.RevokePubKey(ctx, sharedPub)
+	if err != nil || len(revoked) != 1 || revoked[0] != b.ID {
+		t.Errorf("Buckets.RevokePubKey returned %v, %v", revoked, err)
+	}
+	if _, err := sc.Files.List(ctx, b.ID); !storj.IsUnauthorized(err) {
+		t.Errorf("revoked key should be rejected, got %v", err)
+	}
+}
+
+func TestServerFrames(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	c := newTestClient(t, s)
+	ctx := context.Background()
+
+	f, err := c.Frames.New(ctx)
+	if err != nil {
+		t.Fatalf("Frames.New returned error: %v", err)
+	}
+
+	shard := storj.Shard{Index: 0, Hash: "ba084d3f143f2896809d3f1d7dffed472b39d8de", Size: 10}
+	pointer, err := c.Frames.AddShard(ctx, f.ID, shard)
+	if err != nil {
+		t.Fatalf("Frames.AddShard returned error: %v", err)
+	}
+	if pointer.Operation != "PUSH" || pointer.Hash != shard.Hash {
+		t.Errorf("Frames.AddShard returned %+v", pointer)
+	}
+
+	got, err := c.Frames.Get(ctx, f.ID)
+	if err != nil || len(got.Shards) != 1 || got.Size != 10 {
+		t.Errorf("Frames.Get returned %+v, %v", got, err)
+	}
+
+	frames, err := c.Frames.List(ctx)
+	if err != nil || len(frames) != 1 {
+		t.Errorf("Frames.List returned %v, %v", frames, err)
+	}
+
+	if err := c.Frames.Delete(ctx, f.ID); err != nil {
+		t.Errorf("Frames.Delete returned error: %v", err)
+	}
+	if _, err := c.Frames.Get(ctx, f.ID); !storj.IsNotFound(err) {
+		t.Errorf("expected not found after delete, got %v", err)
+	}
+}
+
+func TestServerUpload(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	c := newTestClient(t, s)
+	ctx := context.Background()
+
+	b, err := c.Buckets.New(ctx, "uploads", 10, 20)
+	if err != nil {
+		t.Fatalf("Buckets.New returned error: %v", err)
+	}
+
+	data := []byte("hello, storj")
+	f, err := c.Files.Upload(ctx, b.ID, "hello.txt", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Files.Upload returned error: %v", err)
+	}
+	if f.Name != "hello.txt" || f.Size != int64(len(data)) || f.Bucket != b.ID {
+		t.Errorf("Files.Upload returned %+v", f)
+	}
+
+	if got, ok := s.Shard(storj.ShardHash(data)); !ok || !bytes.Equal(got, data) {
+		t.Errorf("farmer holds %q, expected %q", got, data)
+	}
+
+	files, err := c.Files.List(ctx, b.ID)
+	if err != nil || len(files) != 1 || files[0].ID != f.ID {
+		t.Errorf("Files.List returned %v, %v", files, err)
+	}
+}
+
+func TestServerDownload(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	c := newTestClient(t, s)
+	ctx := context.Background()
+
+	b, err := c.Buckets.New(ctx, "downloads", 10, 20)
+	if err != nil {
+		t.Fatalf("Buckets.New returned error: %v", err)
+	}
+
+	data := bytes.Repeat([]byte("storj"), 1000)
+	f, err := c.Files.Upload(ctx, b.ID, "data.bin", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Files.Upload returned error: %v", err)
+	}
+
+	rc, err := c.Files.Download(ctx, b.ID, f.ID)
+	if err != nil {
+		t.Fatalf("Files.Download returned error: %v", err)
+	}
+	defer rc.Close()
+
+	got, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("reading download returned error: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("downloaded %d bytes that differ from the %d uploaded", len(got), len(data))
+	}
+}
+
+func TestServerEncryptedRoundTrip(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	c := newTestClient(t, s, storj.WithEncryption(mnemonic))
+	ctx := context.Background()
+
+	b, err := c.Buckets.New(ctx, "secrets", 10, 20)
+	if err != nil {
+		t.Fatalf("Buckets.New returned error: %v", err)
+	}
+
+	data := []byte("the plaintext farmers must never see")
+	f, err := c.Files.Upload(ctx, b.ID, "secret.txt", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Files.Upload returned error: %v", err)
+	}
+	if f.Index == "" {
+		t.Errorf("encrypted file has no index")
+	}
+	if _, ok := s.Shard(storj.ShardHash(data)); ok {
+		t.Errorf("farmer holds the plaintext")
+	}
+
+	rc, err := c.Files.Download(ctx, b.ID, f.ID)
+	if err != nil {
+		t.Fatalf("Files.Download returned error: %v", err)
+	}
+	defer rc.Close()
+
+	got, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("reading download returned error: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("downloaded %q, expected %q", got, data)
+	}
+}
+
+func TestServerAEADRoundTrip(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	ctx := context.Background()
+
+	c := newTestClient(t, s, storj.WithEncryption(mnemonic), storj.WithEncryptionMode(storj.EncryptionAEAD))
+	b, err := c.Buckets.New(ctx, "sealed", 10, 20)
+	if err != nil {
+		t.Fatalf("Buckets.New returned error: %v", err)
+	}
+
+	data := bytes.Repeat([]byte("sealed "), 20000)
+	f, err := c.Files.Upload(ctx, b.ID, "sealed.txt", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Files.Upload returned error: %v", err)
+	}
+
+	// A client in the default mode still reads the file.
+	rc, err := newTestClient(t, s, storj.WithEncryption(mnemonic)).Files.Download(ctx, b.ID, f.ID)
+	if err != nil {
+		t.Fatalf("Files.Download returned error: %v", err)
+	}
+	defer rc.Close()
+
+	got, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("reading download returned error: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("downloaded %d bytes that differ from the %d uploaded", len(got), len(data))
+	}
+}
+
+func TestServerCollectGarbageSharedBucket(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	ctx := context.Background()
+	owner := newTestClient(t, s)
+	b, err := owner.Buckets.New(ctx, "shared uploads", 10, 20)
+	if err != nil {
+		t.Fatalf("Buckets.New returned error: %v", err)
+	}
+
+	key, _ := btcec.NewPrivateKey(btcec.S256())
+	uploader, _ := s.NewClient("uploader@storj.io", key)
+	if _, err := owner.Buckets.AddPubKey(ctx, b.ID, storj.PubKeyHex(key.PubKey())); err != nil {
+		t.Fatalf("Buckets.AddPubKey returned error: %v", err)
+	}
+
+	f, err := uploader.Files.Upload(ctx, b.ID, "report.txt", bytes.NewReader([]byte("report")))
+	if err != nil {
+		t.Fatalf("Files.Upload returned error: %v", err)
+	}
+
+	deleted, err := uploader.Frames.CollectGarbage(ctx, storj.GCOptions{GracePeriod: time.Nanosecond})
+	if err != nil || len(deleted) != 0 {
+		t.Errorf("Frames.CollectGarbage deleted %v, %v; expected nothing", deleted, err)
+	}
+	if _, err := uploader.Frames.Get(ctx, f.Frame); err != nil {
+		t.Errorf("frame of the uploaded file is gone: %v", err)
+	}
+}
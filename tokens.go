@@ -1,11 +1,8 @@
 package storj
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
-	"net/url"
 	"time"
 )
 
@@ -20,35 +17,15 @@ type Token struct {
 	Operation string    `json:"operation"`
 }
 
-func (s *TokenService) New(operation, bucketID string) (*Token, error) {
-	nonce, err := s.client.generateNonce()
-	if err != nil {
-		return nil, err
-	}
-
+func (s *TokenService) New(ctx context.Context, operation, bucketID string) (*Token, error) {
 	b := struct {
 		Operation string `json:"operation"`
-		Nonce     string `json:"__nonce"`
 	}{
 		operation,
-		nonce,
-	}
-
-	j, err := json.Marshal(&b)
-	if err != nil {
-		return nil, err
-	}
-
-	rel, _ := url.Parse(fmt.Sprintf("/buckets/%s/tokens", bucketID))
-	url := s.client.BaseURL.ResolveReference(rel)
-	req, err := http.NewRequest("POST", url.String(), bytes.NewBuffer(j))
-	if err != nil {
-		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	msg := fmt.Sprintf("POST\n/buckets/%s/tokens\n%s", bucketID, j)
-	err = s.client.signRequest(req, msg)
+	path := fmt.Sprintf("/buckets/%s/tokens", bucketID)
+	req, err := s.client.newSignedBodyRequest(ctx, "POST", path, &b)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package storj
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -30,7 +31,7 @@ func TestTokensNew(t *testing.T) {
 	setup()
 	defer teardown()
 
-	_, err := client.Tokens.New("PULL", "buket_id")
+	_, err := client.Tokens.New(context.Background(), "PULL", "buket_id")
 	if err == nil || err.Error() != "authentication required" {
 		t.Errorf("Tokens.New should require authentication")
 	}
@@ -60,7 +61,7 @@ func TestTokensNew(t *testing.T) {
 		fmt.Fprintf(w, tokenJson)
 	})
 
-	token, err := client.Tokens.New("PULL", "bucket_id")
+	token, err := client.Tokens.New(context.Background(), "PULL", "bucket_id")
 	if err != nil {
 		t.Errorf("Tokens.New returned error: %v", err)
 	}
@@ -0,0 +1,241 @@
+package storj
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"golang.org/x/crypto/ripemd160"
+)
+
+// DefaultShardSize is the size of each shard Upload splits a file into. The
+// last shard holds whatever is left over.
+const DefaultShardSize = 8 << 20
+
+// ShardChallenges is the number of audit challenges generated for each
+// shard, matching libstorj.
+const ShardChallenges = 4
+
+// shardSize is a variable so tests can upload several shards cheaply.
+var shardSize = DefaultShardSize
+
+// ShardHash returns the hex-encoded RIPEMD-160 of the SHA-256 of data, the
+// hash the Bridge and farmers identify a shard by.
+func ShardHash(data []byte) string {
+	return hex.EncodeToString(rmd160sha256(data))
+}
+
+func rmd160sha256(data []byte) []byte {
+	sha := sha256.Sum256(data)
+	rmd := ripemd160.New()
+	rmd.Write(sha[:])
+	return rmd.Sum(nil)
+}
+
+// newShard describes data as the shard at index, generating random audit
+// challenges and the tree leaves a farmer must reproduce to answer them.
+func newShard(index int, data []byte) (Shard, error) {
+	sh := Shard{
+		Index:      index,
+		Hash:       ShardHash(data),
+		Size:       int64(len(data)),
+		Tree:       make([]string, ShardChallenges),
+		Challenges: make([]string, ShardChallenges),
+	}
+
+	for i := 0; i < ShardChallenges; i++ {
+		challenge := make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, challenge); err != nil {
+			return Shard{}, err
+		}
+
+		preleaf := rmd160sha256(append(challenge, data...))
+		sh.Challenges[i] = hex.EncodeToString(challenge)
+		sh.Tree[i] = hex.EncodeToString(rmd160sha256(preleaf))
+	}
+
+	return sh, nil
+}
+
+// frameCleanupTimeout bounds the frame deletion Upload does after a failure.
+// It runs on its own context, so it still happens when ctx was canceled.
+const frameCleanupTimeout = 30 * time.Second
+
+// Upload stores the contents of r in a bucket as a file called name. It
+// splits the data into shards, has the Bridge assign each one a farmer,
+// pushes the shards to those farmers and finally adds the file to the
+// bucket. If the upload fails, the partly filled frame is deleted on a best
+// effort basis; FrameService.CollectGarbage removes any that remain.
+//
+// If the client was created with WithEncryption, the data is encrypted before
+// it leaves the process, in the mode set by WithEncryptionMode.
+func (s *FileService) Upload(ctx context.Context, bucketID, name string, r io.Reader) (*File, error) {
+	mimeType, r, err := detectMimeType(name, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var index string
+	if s.client.seed != nil {
+		if index, err = newIndex(); err != nil {
+			return nil, err
+		}
+		if r, err = s.encrypt(bucketID, index, r); err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := s.client.Tokens.New(ctx, "PUSH", bucketID)
+	if err != nil {
+		return nil, err
+	}
+
+	frame, err := s.client.Frames.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := s.upload(ctx, bucketID, name, mimeType, index, token.Token, frame.ID, r)
+	if err != nil {
+		cctx, cancel := context.WithTimeout(context.Background(), frameCleanupTimeout)
+		defer cancel()
+
+		s.client.Frames.Delete(cctx, frame.ID)
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// encrypt wraps r to encrypt it in the client's encryption mode, with the
+// key for a file with the given index.
+func (s *FileService) encrypt(bucketID, index string, r io.Reader) (io.Reader, error) {
+	if s.client.encryptionMode == EncryptionAEAD {
+		key, err := aeadKey(s.client.seed, bucketID, index)
+		if err != nil {
+			return nil, err
+		}
+		return newAEADEncrypter(key, r)
+	}
+
+	stream, err := fileStream(s.client.seed, bucketID, &File{Index: index})
+	if err != nil {
+		return nil, err
+	}
+	return &cipher.StreamReader{S: stream, R: r}, nil
+}
+
+// detectMimeType guesses the type of a file from its name, or failing that,
+// from its first bytes. The returned reader replaces r.
+func detectMimeType(name string, r io.Reader) (string, io.Reader, error) {
+	if t := mime.TypeByExtension(path.Ext(name)); t != "" {
+		return t, r, nil
+	}
+
+	br := bufio.NewReader(r)
+	head, err := br.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return "", nil, err
+	}
+	if len(head) == 0 {
+		return "application/octet-stream", br, nil
+	}
+
+	return http.DetectContentType(head), br, nil
+}
+
+func (s *FileService) upload(ctx context.Context, bucketID, name, mimeType, index, token, frameID string, r io.Reader) (*File, error) {
+	buf := make([]byte, shardSize)
+
+	for i := 0; ; i++ {
+		n, err := io.ReadFull(r, buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		data := buf[:n]
+
+		sh, err := newShard(i, data)
+		if err != nil {
+			return nil, err
+		}
+
+		pointer, err := s.client.Frames.AddShard(ctx, frameID, sh)
+		if err != nil {
+			return nil, fmt.Errorf("shard %d: %w", i, err)
+		}
+
+		if err := s.pushShard(ctx, pointer, data); err != nil {
+			return nil, fmt.Errorf("shard %d: %w", i, err)
+		}
+
+		if n < len(buf) {
+			break
+		}
+	}
+
+	return s.create(ctx, bucketID, token, frameID, name, mimeType, index)
+}
+
+// pushShard sends a shard's data to the farmer the Bridge assigned it to.
+func (s *FileService) pushShard(ctx context.Context, p *FilePointer, data []byte) error {
+	req, err := http.NewRequest("POST", shardURL(p), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("x-storj-node-id", p.Farmer.NodeID)
+
+	_, err = s.client.Do(req, nil)
+	return err
+}
+
+// shardURL is the farmer endpoint for the shard p points to.
+func shardURL(p *FilePointer) string {
+	return fmt.Sprintf("http://%s:%d/shards/%s?token=%s", p.Farmer.Address, p.Farmer.Port, p.Hash, url.QueryEscape(p.Token))
+}
+
+// create adds a file made of the shards in a frame to a bucket. index is
+// empty for unencrypted files.
+func (s *FileService) create(ctx context.Context, bucketID, token, frameID, name, mimeType, index string) (*File, error) {
+	b := struct {
+		Frame    string `json:"frame"`
+		MimeType string `json:"mimetype"`
+		Name     string `json:"filename"`
+		Index    string `json:"index,omitempty"`
+	}{
+		frameID,
+		mimeType,
+		name,
+		index,
+	}
+
+	path := fmt.Sprintf("/buckets/%s/files", bucketID)
+	req, err := s.client.newSignedBodyRequest(ctx, "POST", path, &b)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-token", token)
+
+	var f File
+	_, err = s.client.Do(req, &f)
+	if err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
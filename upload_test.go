@@ -0,0 +1,208 @@
+package storj
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestShardHash(t *testing.T) {
+	// RIPEMD-160 of the SHA-256 of the empty string.
+	if h := ShardHash(nil); h != "b472a266d0bd89c13706a4132ccfb16f7c3b9fcb" {
+		t.Errorf("ShardHash(nil) = %s", h)
+	}
+}
+
+func TestNewShard(t *testing.T) {
+	data := []byte("some shard data")
+
+	sh, err := newShard(3, data)
+	if err != nil {
+		t.Fatalf("newShard returned error: %v", err)
+	}
+
+	if sh.Index != 3 || sh.Size != int64(len(data)) || sh.Hash != ShardHash(data) {
+		t.Errorf("newShard returned %+v", sh)
+	}
+	if len(sh.Challenges) != ShardChallenges || len(sh.Tree) != ShardChallenges {
+		t.Fatalf("expected %d challenges and leaves, got %d and %d", ShardChallenges, len(sh.Challenges), len(sh.Tree))
+	}
+
+	for i, c := range sh.Challenges {
+		challenge, _ := hex.DecodeString(c)
+		preleaf := rmd160sha256(append(challenge, data...))
+		if leaf := hex.EncodeToString(rmd160sha256(preleaf)); leaf != sh.Tree[i] {
+			t.Errorf("leaf %d is %s, expected %s", i, sh.Tree[i], leaf)
+		}
+	}
+}
+
+// handleUpload serves the Bridge and farmer endpoints an upload uses,
+// returning the shard data the farmer received by hash.
+func handleUpload(t *testing.T) (shards map[string][]byte, created *map[string]string) {
+	var mu sync.Mutex
+	shards = make(map[string][]byte)
+	created = new(map[string]string)
+
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	createFile := func(w http.ResponseWriter, r *http.Request) {
+		assertHeader(t, r, "x-token", "push_token")
+		json.NewDecoder(r.Body).Decode(created)
+		fmt.Fprintf(w, `{"id": "file_id", "bucket": "xyz", "filename": %q, "mimetype": %q, "frame": "frame_id", "size": 10}`,
+			(*created)["filename"], (*created)["mimetype"])
+	}
+
+	mux.HandleFunc("/buckets/", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "POST")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/tokens"):
+			fmt.Fprint(w, `{"token": "push_token", "operation": "PUSH"}`)
+		case strings.HasSuffix(r.URL.Path, "/files"):
+			createFile(w, r)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	})
+	mux.HandleFunc("/frames", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "POST")
+		fmt.Fprint(w, `{"id": "frame_id"}`)
+	})
+	mux.HandleFunc("/frames/frame_id", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "PUT")
+		var sh Shard
+		json.NewDecoder(r.Body).Decode(&sh)
+		fmt.Fprintf(w, `{"hash": %q, "token": "t%d", "operation": "PUSH", "farmer": {"address": %q, "port": %s, "nodeID": "node"}}`,
+			sh.Hash, sh.Index, host, port)
+	})
+	mux.HandleFunc("/shards/", func(w http.ResponseWriter, r *http.Request) {
+		assertMethod(t, r, "POST")
+		assertHeader(t, r, "x-storj-node-id", "node")
+		if !strings.HasPrefix(r.URL.Query().Get("token"), "t") {
+			t.Errorf("shard pushed without its token")
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+
+		mu.Lock()
+		shards[strings.TrimPrefix(r.URL.Path, "/shards/")] = data
+		mu.Unlock()
+	})
+	return shards, created
+}
+
+func TestFilesUpload(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	defer func(n int) { shardSize = n }(shardSize)
+	shardSize = 4
+
+	shards, created := handleUpload(t)
+
+	data := []byte("0123456789")
+	f, err := client.Files.Upload(context.Background(), "xyz", "digits.txt", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Files.Upload returned error: %v", err)
+	}
+
+	if f.ID != "file_id" || f.Frame != "frame_id" {
+		t.Errorf("Files.Upload returned %+v", f)
+	}
+
+	if (*created)["frame"] != "frame_id" || (*created)["filename"] != "digits.txt" ||
+		!strings.HasPrefix((*created)["mimetype"], "text/plain") {
+		t.Errorf("created file with %v", *created)
+	}
+
+	for _, piece := range []string{"0123", "4567", "89"} {
+		got, ok := shards[ShardHash([]byte(piece))]
+		if !ok || string(got) != piece {
+			t.Errorf("farmer did not receive shard %q", piece)
+		}
+	}
+	if len(shards) != 3 {
+		t.Errorf("farmer received %d shards, expected 3", len(shards))
+	}
+}
+
+func TestFilesUploadFailureDeletesFrame(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	mux.HandleFunc("/buckets/xyz/tokens", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"token": "push_token"}`)
+	})
+	mux.HandleFunc("/frames", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "frame_id"}`)
+	})
+
+	frameDeleted := false
+	mux.HandleFunc("/frames/frame_id", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			frameDeleted = true
+			w.WriteHeader(204)
+			return
+		}
+		w.WriteHeader(400)
+		fmt.Fprint(w, `{"error": "Frame is locked"}`)
+	})
+
+	_, err := client.Files.Upload(context.Background(), "xyz", "a.bin", strings.NewReader("data"))
+	if err == nil || !strings.Contains(err.Error(), "Frame is locked") {
+		t.Errorf("expected shard error, got %v", err)
+	}
+	if !frameDeleted {
+		t.Errorf("failed upload did not delete its frame")
+	}
+}
+
+func TestFilesUploadCanceledDeletesFrame(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mux.HandleFunc("/buckets/xyz/tokens", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"token": "push_token"}`)
+	})
+	mux.HandleFunc("/frames", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "frame_id"}`)
+	})
+
+	frameDeleted := false
+	mux.HandleFunc("/frames/frame_id", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			frameDeleted = true
+			w.WriteHeader(204)
+			return
+		}
+		cancel()
+		w.WriteHeader(500)
+	})
+
+	_, err := client.Files.Upload(ctx, "xyz", "a.bin", strings.NewReader("data"))
+	if err == nil {
+		t.Errorf("Files.Upload should fail when canceled")
+	}
+	if !frameDeleted {
+		t.Errorf("canceled upload did not delete its frame")
+	}
+}
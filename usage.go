@@ -0,0 +1,170 @@
+package storj
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// BucketLimitUnit is the number of bytes in one unit of Bucket.Storage and
+// Bucket.Transfer, which the Bridge expresses in gigabytes.
+const BucketLimitUnit = 1000 * 1000 * 1000
+
+// Default thresholds for UsageOptions.
+const (
+	DefaultUsageWarning  = 0.80
+	DefaultUsageCritical = 0.95
+)
+
+// UsageLevel classifies how close a bucket is to its storage quota.
+type UsageLevel string
+
+const (
+	UsageOK       UsageLevel = "ok"
+	UsageWarning  UsageLevel = "warning"
+	UsageCritical UsageLevel = "critical"
+	UsageOver     UsageLevel = "over"
+	UsageNoQuota  UsageLevel = "no-quota"
+)
+
+// UsageOptions configures BucketService.Usage. Thresholds are fractions of
+// the storage quota; zero values use the defaults.
+type UsageOptions struct {
+	Warning  float64
+	Critical float64
+}
+
+// BucketUsage is the stored size of one bucket compared to its quota.
+type BucketUsage struct {
+	BucketID    string     `json:"bucketId"`
+	Name        string     `json:"name"`
+	Files       int        `json:"files"`
+	StoredBytes int64      `json:"storedBytes"`
+	QuotaBytes  int64      `json:"quotaBytes"`
+	Headroom    int64      `json:"headroomBytes"`
+	Used        float64    `json:"used"`
+	Level       UsageLevel `json:"level"`
+}
+
+// UsageReport summarizes storage use across every bucket.
+type UsageReport struct {
+	Generated time.Time     `json:"generated"`
+	Buckets   []BucketUsage `json:"buckets"`
+}
+
+// Usage sums the size of the files in every bucket and compares it to the
+// bucket's storage quota. A bucket with no quota is reported as UsageNoQuota.
+func (s *BucketService) Usage(ctx context.Context, opts UsageOptions) (*UsageReport, error) {
+	if opts.Warning <= 0 {
+		opts.Warning = DefaultUsageWarning
+	}
+	if opts.Critical <= 0 {
+		opts.Critical = DefaultUsageCritical
+	}
+
+	buckets, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UsageReport{Generated: time.Now().UTC()}
+	for _, b := range buckets {
+		files, err := s.client.Files.List(ctx, b.ID)
+		if err != nil {
+			return nil, fmt.Errorf("bucket %s: %w", b.ID, err)
+		}
+
+		report.Buckets = append(report.Buckets, bucketUsage(b, files, opts))
+	}
+
+	return report, nil
+}
+
+func bucketUsage(b Bucket, files []File, opts UsageOptions) BucketUsage {
+	u := BucketUsage{
+		BucketID:   b.ID,
+		Name:       b.Name,
+		Files:      len(files),
+		QuotaBytes: int64(b.Storage) * BucketLimitUnit,
+	}
+	for _, f := range files {
+		u.StoredBytes += f.Size
+	}
+
+	if u.QuotaBytes <= 0 {
+		u.Level = UsageNoQuota
+		return u
+	}
+
+	u.Headroom = u.QuotaBytes - u.StoredBytes
+	if u.Headroom < 0 {
+		u.Headroom = 0
+	}
+	u.Used = float64(u.StoredBytes) / float64(u.QuotaBytes)
+
+	switch {
+	case u.Used > 1:
+		u.Level = UsageOver
+	case u.Used >= opts.Critical:
+		u.Level = UsageCritical
+	case u.Used >= opts.Warning:
+		u.Level = UsageWarning
+	default:
+		u.Level = UsageOK
+	}
+
+	return u
+}
+
+// Flagged returns the buckets at or above the warning threshold.
+func (r *UsageReport) Flagged() []BucketUsage {
+	var flagged []BucketUsage
+	for _, u := range r.Buckets {
+		if u.Level == UsageWarning || u.Level == UsageCritical || u.Level == UsageOver {
+			flagged = append(flagged, u)
+		}
+	}
+	return flagged
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *UsageReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteTable writes the report as an aligned plain-text table.
+func (r *UsageReport) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tID\tFILES\tSTORED\tQUOTA\tUSED\tHEADROOM\tLEVEL")
+	for _, u := range r.Buckets {
+		quota, used, headroom := "-", "-", "-"
+		if u.Level != UsageNoQuota {
+			quota = formatBytes(u.QuotaBytes)
+			used = fmt.Sprintf("%.1f%%", 100*u.Used)
+			headroom = formatBytes(u.Headroom)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			u.Name, u.BucketID, u.Files, formatBytes(u.StoredBytes), quota, used, headroom, u.Level)
+	}
+	return tw.Flush()
+}
+
+// formatBytes renders n in decimal units, matching BucketLimitUnit.
+func formatBytes(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
@@ -0,0 +1,93 @@
+package storj
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBucketsUsage(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	mux.HandleFunc("/buckets", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+  {"id": "a", "name": "half", "storage": 10},
+  {"id": "b", "name": "nearly", "storage": 1},
+  {"id": "c", "name": "unlimited", "storage": 0}]`)
+	})
+	files := map[string]string{
+		"a": `[{"id": "1", "size": 3000000000}, {"id": "2", "size": 2000000000}]`,
+		"b": `[{"id": "3", "size": 900000000}]`,
+		"c": `[{"id": "4", "size": 42}]`,
+	}
+	for id, body := range files {
+		body := body
+		mux.HandleFunc("/buckets/"+id+"/files", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, body)
+		})
+	}
+
+	report, err := client.Buckets.Usage(context.Background(), UsageOptions{Warning: 0.85})
+	if err != nil {
+		t.Fatalf("Buckets.Usage returned error: %v", err)
+	}
+	if len(report.Buckets) != 3 {
+		t.Fatalf("expected 3 buckets, got %d", len(report.Buckets))
+	}
+
+	half := report.Buckets[0]
+	if half.StoredBytes != 5000000000 || half.Headroom != 5000000000 || half.Used != 0.5 || half.Level != UsageOK {
+		t.Errorf("unexpected usage for half-full bucket: %+v", half)
+	}
+	if nearly := report.Buckets[1]; nearly.Level != UsageWarning {
+		t.Errorf("expected warning for 90%% full bucket, got %+v", nearly)
+	}
+	if unlimited := report.Buckets[2]; unlimited.Level != UsageNoQuota || unlimited.StoredBytes != 42 {
+		t.Errorf("unexpected usage for unlimited bucket: %+v", unlimited)
+	}
+
+	flagged := report.Flagged()
+	if len(flagged) != 1 || flagged[0].BucketID != "b" {
+		t.Errorf("Flagged returned %+v", flagged)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON returned error: %v", err)
+	}
+	var decoded UsageReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.Buckets) != 3 {
+		t.Errorf("WriteJSON produced unreadable output: %v", err)
+	}
+
+	buf.Reset()
+	if err := report.WriteTable(&buf); err != nil {
+		t.Fatalf("WriteTable returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || !strings.Contains(lines[2], "90.0%") || !strings.Contains(lines[2], "warning") {
+		t.Errorf("unexpected table:\n%s", buf.String())
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:          "0 B",
+		999:        "999 B",
+		1500:       "1.5 kB",
+		5000000000: "5.0 GB",
+	}
+	for n, expected := range tests {
+		if s := formatBytes(n); s != expected {
+			t.Errorf("formatBytes(%d) = %q, expected %q", n, s, expected)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package storj
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	msg := []byte("GET\n/buckets\n__nonce=abc")
+	sha := sha256.Sum256(msg)
+	sig, _ := privKey.Sign(sha[:])
+
+	pubKey := PubKeyHex(privKey.PubKey())
+	sigHex := hex.EncodeToString(sig.Serialize())
+
+	if err := Verify(pubKey, msg, sigHex); err != nil {
+		t.Errorf("Verify returned error: %v", err)
+	}
+	if err := Verify(pubKey, []byte("GET\n/buckets\n__nonce=abd"), sigHex); err != ErrInvalidSignature {
+		t.Errorf("Verify should reject a modified message, got %v", err)
+	}
+	if err := Verify("zz", msg, sigHex); err == nil {
+		t.Errorf("Verify should reject a malformed public key")
+	}
+}
+
+func TestVerifySignatures(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enableAuth()
+	defer disableAuth()
+
+	var requests []*http.Request
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pubKey, ok := PubKeyFromContext(r.Context())
+		if !ok || pubKey != PubKeyHex(privKey.PubKey()) {
+			t.Errorf("handler got public key %q, %v", pubKey, ok)
+		}
+		requests = append(requests, r)
+
+		if r.Method == "POST" {
+			fmt.Fprint(w, bucketJson)
+			return
+		}
+		fmt.Fprint(w, "[]")
+	})
+	mux.Handle("/buckets", VerifySignatures(handler, NewNonceStore(0)))
+
+	ctx := context.Background()
+	if _, err := client.Buckets.List(ctx); err != nil {
+		t.Errorf("Buckets.List returned error: %v", err)
+	}
+	if _, err := client.Buckets.New(ctx, "verified", 1, 1); err != nil {
+		t.Errorf("Buckets.New returned error: %v", err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 verified requests, got %d", len(requests))
+	}
+
+	// Replay the GET exactly as it was sent.
+	replay, _ := http.NewRequest("GET", server.URL+requests[0].URL.String(), nil)
+	replay.Header = requests[0].Header
+	_, err := client.Do(replay, nil)
+	if !IsUnauthorized(err) {
+		t.Errorf("replayed request should be rejected, got %v", err)
+	}
+
+	// Forge a request with a valid header format but the wrong signature.
+	forged, _ := http.NewRequest("GET", server.URL+"/buckets?__nonce=fresh", nil)
+	forged.Header = requests[0].Header
+	_, err = client.Do(forged, nil)
+	if !IsUnauthorized(err) {
+		t.Errorf("forged request should be rejected, got %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Errorf("rejected requests reached the handler")
+	}
+}
+
+func TestNonceStore(t *testing.T) {
+	s := NewNonceStore(0)
+	if !s.Use("a") || s.Use("a") || !s.Use("b") {
+		t.Errorf("NonceStore should accept each nonce exactly once")
+	}
+}
+
+func TestVerifySignaturesBodyLimit(t *testing.T) {
+	handler := VerifySignatures(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("handler called for an oversized request")
+	}), NewNonceStore(0))
+
+	body := strings.NewReader(strings.Repeat("x", MaxVerifiedBodySize+1))
+	r := httptest.NewRequest("POST", "/buckets", body)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("got status %d, expected %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}